@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"marketflow/internal/application/usecases"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/symbols"
+)
+
+// configFlags holds the flags that select and override configuration
+type configFlags struct {
+	path      *string
+	port      *int
+	overrides []string
+	fs        *flag.FlagSet
+}
+
+// registerConfigFlags adds --config, --set and --port to a flag set
+func registerConfigFlags(fs *flag.FlagSet) *configFlags {
+	f := &configFlags{fs: fs}
+	f.path = fs.String("config", "", "Configuration file (YAML or JSON)")
+	f.port = fs.Int("port", 8080, "Port number")
+	fs.Func("set", "Override a configuration key, e.g. --set server.port=9090 (repeatable)", func(value string) error {
+		f.overrides = append(f.overrides, value)
+		return nil
+	})
+	return f
+}
+
+// options returns the load options; --port wins over every other source, but only when given explicitly
+func (f *configFlags) options() config.Options {
+	overrides := append([]string{}, f.overrides...)
+	f.fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == "port" {
+			overrides = append(overrides, "server.port="+strconv.Itoa(*f.port))
+		}
+	})
+	return config.Options{Path: *f.path, Overrides: overrides}
+}
+
+// runConfigCommand implements "marketflow config <subcommand>" and returns the exit code
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, "Usage: marketflow config validate [--config <file>] [--set key=value]...")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	flags := registerConfigFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(flags.options())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	source := cfg.File
+	if source == "" {
+		source = "built-in defaults"
+	}
+
+	// The registry derives the assets of symbols that do not give them
+	if _, err := symbols.NewRegistry(usecases.TrackedSymbols(cfg.Symbols)); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: symbols: %v\n", source, err)
+		return 1
+	}
+
+	fmt.Printf("%s: configuration is valid\n", source)
+	return 0
+}
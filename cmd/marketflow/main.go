@@ -2,29 +2,32 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	//"log/slog"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"marketflow/internal/adapters/cache/memory"
 	"marketflow/internal/adapters/cache/redis"
 	"marketflow/internal/adapters/exchange/live"
 	"marketflow/internal/adapters/exchange/test"
 	"marketflow/internal/adapters/storage/postgresql"
+	"marketflow/internal/adapters/storage/spool"
 	"marketflow/internal/adapters/web"
+	"marketflow/internal/application/ports"
 	"marketflow/internal/application/usecases"
 	"marketflow/internal/concurrency"
 	"marketflow/internal/config"
+	"marketflow/internal/domain/symbols"
 	"marketflow/internal/logger"
 )
 
 func main() {
-	var (
-		port = flag.Int("port", 8080, "Port number")
-		help = flag.Bool("help", false, "Show help")
-	)
+	configFlags := registerConfigFlags(flag.CommandLine)
+	help := flag.Bool("help", false, "Show help")
 	flag.Parse()
 
 	if *help {
@@ -32,49 +35,94 @@ func main() {
 		return
 	}
 
-	// Initialize logger
-	log := logger.New()
+	if flag.Arg(0) == "config" {
+		os.Exit(runConfigCommand(flag.Args()[1:]))
+	}
+
+	if flag.Arg(0) == "migrate" {
+		os.Exit(runMigrateCommand(flag.Args()[1:]))
+	}
+
+	// Initialize logger; the level follows logging.level and can change on reload
+	logLevel := new(slog.LevelVar)
+	log := logger.New(logLevel)
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.Load(configFlags.options())
 	if err != nil {
-		log.Error("Failed to load configuration", "error", err)
+		var cfgErr *config.Error
+		if errors.As(err, &cfgErr) {
+			log.Error("Invalid configuration", "file", cfgErr.File, "problems", cfgErr.Problems)
+		} else {
+			log.Error("Failed to load configuration", "error", err)
+		}
 		os.Exit(1)
 	}
+	level, _ := cfg.Logging.SlogLevel()
+	logLevel.Set(level)
+	log.Info("Configuration loaded", "file", cfg.File)
 
 	// Initialize components
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Initialize storage
-	storage, err := postgresql.New(cfg.Database)
+	// Initialize storage; an unreachable database is retried in the background
+	storage, err := postgresql.New(cfg.Database.Postgres, log)
 	if err != nil {
 		log.Error("Failed to initialize storage", "error", err)
 		os.Exit(1)
 	}
+
+	// Keep batches the database rejects on disk until it recovers
+	if cfg.Database.Spool.Enabled {
+		storage, err = spool.New(storage, cfg.Database.Spool, postgresql.IsUnavailable, log)
+		if err != nil {
+			log.Error("Failed to initialize spool", "error", err)
+			os.Exit(1)
+		}
+	}
 	defer storage.Close()
 
-	// Initialize cache
-	cache, err := redis.New(cfg.Cache)
+	// Initialize cache; an unreachable Redis is retried in the background
+	redisCache, err := redis.New(cfg.Database.Redis, cfg.Processing.HistoryRetention(), log)
 	if err != nil {
 		log.Error("Failed to initialize cache", "error", err)
 		os.Exit(1)
 	}
-	defer cache.Close()
+	defer redisCache.Close()
+
+	// Keep serving latest prices from memory while Redis is down
+	cache := memory.NewFallback(redisCache)
+
+	// Initialize the symbol registry shared by the generator, aggregator and API
+	registry, err := symbols.NewRegistry(usecases.TrackedSymbols(cfg.Symbols))
+	if err != nil {
+		log.Error("Failed to initialize symbol registry", "error", err)
+		os.Exit(1)
+	}
 
 	// Initialize exchange adapters
 	liveExchange := live.New(cfg.Exchanges)
-	testExchange := test.New()
+	testExchange := test.New(cfg.Exchanges.Backpressure.Test, registry)
 
 	// Initialize concurrency manager
 	concurrencyManager := concurrency.NewManager(log)
 
+	// Initialize the broadcaster feeding processed updates to stream clients
+	broadcaster := concurrency.NewBroadcaster(cfg.Server.Stream.ReplayBuffer, cfg.Server.Stream.ClientBuffer)
+
 	// Initialize use cases
-	marketDataUseCase := usecases.NewMarketDataUseCase(storage, cache, log)
-	dataProcessingUseCase := usecases.NewDataProcessingUseCase(storage, cache, concurrencyManager, log)
+	marketDataUseCase := usecases.NewMarketDataUseCase(storage, cache, []ports.ExchangePort{liveExchange, testExchange}, registry, cfg.Processing.CandleDurations(), broadcaster, log)
+	dataProcessingUseCase := usecases.NewDataProcessingUseCase(storage, cache, concurrencyManager, broadcaster, cfg.Processing, registry, log)
+	dataProcessingUseCase.OnCandles(marketDataUseCase.PublishCandles)
+	symbolsUseCase := usecases.NewSymbolsUseCase(registry, log)
+	loadOptions := configFlags.options()
+	reloadUseCase := usecases.NewReloadUseCase(func() (*config.Config, error) {
+		return config.Load(loadOptions)
+	}, cfg, dataProcessingUseCase, registry, logLevel, log)
 
 	// Initialize web server
-	webServer := web.NewServer(*port, marketDataUseCase, dataProcessingUseCase, log)
+	webServer := web.NewServer(cfg.Server, marketDataUseCase, dataProcessingUseCase, reloadUseCase, symbolsUseCase, log)
 
 	// Start data processing
 	go func() {
@@ -84,6 +132,9 @@ func main() {
 		}
 	}()
 
+	// Derive ticks and spreads for WebSocket subscribers from the price broadcast
+	go marketDataUseCase.RunLiveFeed(ctx)
+
 	// Start web server
 	go func() {
 		if err := webServer.Start(); err != nil {
@@ -92,6 +143,18 @@ func main() {
 		}
 	}()
 
+	// Reload configuration on SIGHUP
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			log.Info("Received SIGHUP, reloading configuration")
+			if _, err := reloadUseCase.Reload(); err != nil {
+				log.Error("Failed to reload configuration", "error", err)
+			}
+		}
+	}()
+
 	// Wait for shutdown signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -111,9 +174,17 @@ func main() {
 
 func printUsage() {
 	fmt.Println("Usage:")
-	fmt.Println("  marketflow [--port <N>]")
+	fmt.Println("  marketflow [--port <N>] [--config <file>] [--set key=value]...")
+	fmt.Println("  marketflow config validate [--config <file>] [--set key=value]...")
+	fmt.Println("  marketflow migrate up|down [N]|status [--config <file>] [--set key=value]...")
 	fmt.Println("  marketflow --help")
 	fmt.Println()
 	fmt.Println("Options:")
-	fmt.Println("  --port N     Port number")
+	fmt.Println("  --port N          Port number (overrides server.port)")
+	fmt.Println("  --config FILE     Configuration file, YAML or JSON (default: $CONFIG_FILE,")
+	fmt.Println("                    then config.yaml, configs/config.yaml, configs/config.json)")
+	fmt.Println("  --set KEY=VALUE   Override a configuration key, e.g. --set processing.batch_size=500")
+	fmt.Println()
+	fmt.Println("Every key can also be set with a MARKETFLOW_ environment variable,")
+	fmt.Println("e.g. MARKETFLOW_DATABASE_POSTGRES_HOST=db or MARKETFLOW_EXCHANGES_LIVE_0_PORT=40111")
 }
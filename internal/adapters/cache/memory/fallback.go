@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"marketflow/internal/application/ports"
+	"marketflow/internal/domain/models"
+)
+
+// latestTTL matches how long the Redis adapter keeps a latest price
+const latestTTL = 2 * time.Minute
+
+// Fallback wraps a cache and keeps the latest prices in memory as well, so
+// they can still be served while the wrapped cache is unreachable
+type Fallback struct {
+	ports.CachePort
+
+	mu     sync.RWMutex
+	latest map[string]map[string]models.LatestPrice // symbol -> exchange -> price
+}
+
+// NewFallback wraps cache with an in-memory copy of the latest prices
+func NewFallback(cache ports.CachePort) *Fallback {
+	return &Fallback{
+		CachePort: cache,
+		latest:    make(map[string]map[string]models.LatestPrice),
+	}
+}
+
+// SetLatestPrice records the price in memory and, when it is reachable, in the wrapped cache
+func (f *Fallback) SetLatestPrice(ctx context.Context, update models.PriceUpdate) error {
+	f.mu.Lock()
+	byExchange, ok := f.latest[update.Symbol]
+	if !ok {
+		byExchange = make(map[string]models.LatestPrice)
+		f.latest[update.Symbol] = byExchange
+	}
+	byExchange[update.Exchange] = models.LatestPrice{
+		Symbol:    update.Symbol,
+		Exchange:  update.Exchange,
+		Price:     update.Price,
+		Timestamp: update.ReceivedAt,
+	}
+	f.mu.Unlock()
+
+	// Skip the wrapped cache while it is down rather than failing every update
+	if !f.up() {
+		return nil
+	}
+	return f.CachePort.SetLatestPrice(ctx, update)
+}
+
+// GetLatestPrice reads from the wrapped cache, or from memory while it is unreachable
+func (f *Fallback) GetLatestPrice(ctx context.Context, symbol, exchange string) (*models.LatestPrice, error) {
+	if f.up() {
+		if price, err := f.CachePort.GetLatestPrice(ctx, symbol, exchange); err == nil {
+			return price, nil
+		}
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	price, ok := f.latest[symbol][exchange]
+	if !ok || time.Since(price.Timestamp) > latestTTL {
+		return nil, nil
+	}
+	return &price, nil
+}
+
+// GetLatestPrices reads from the wrapped cache, or from memory while it is unreachable
+func (f *Fallback) GetLatestPrices(ctx context.Context, symbol string) ([]*models.LatestPrice, error) {
+	if f.up() {
+		if prices, err := f.CachePort.GetLatestPrices(ctx, symbol); err == nil {
+			return prices, nil
+		}
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	prices := []*models.LatestPrice{}
+	for _, price := range f.latest[symbol] {
+		if time.Since(price.Timestamp) > latestTTL {
+			continue
+		}
+		price := price
+		prices = append(prices, &price)
+	}
+	return prices, nil
+}
+
+func (f *Fallback) up() bool {
+	return f.CachePort.Health().State == models.DependencyUp
+}
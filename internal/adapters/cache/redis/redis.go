@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 
+	"marketflow/internal/adapters/monitor"
 	"marketflow/internal/application/ports"
 	"marketflow/internal/config"
 	"marketflow/internal/domain/models"
@@ -15,28 +17,42 @@ import (
 
 // Adapter implements the CachePort interface for Redis
 type Adapter struct {
-	client *redis.Client
+	client    *redis.Client
+	monitor   *monitor.Monitor
+	retention time.Duration // how long price history is kept
 }
 
-// New creates a new Redis adapter
-func New(cfg config.CacheConfig) (ports.CachePort, error) {
+// latestPriceTTL is how long a latest price is served after its update
+const latestPriceTTL = 2 * time.Minute
+
+// New creates a new Redis adapter that keeps price history for retention. It
+// does not fail when Redis is unreachable: the connection is retried in the
+// background and until then commands return errors.
+func New(cfg config.RedisConfig, retention time.Duration, logger *slog.Logger) (ports.CachePort, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
 		Password: cfg.Password,
 		DB:       cfg.Database,
 	})
 
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	a := &Adapter{
+		client:    client,
+		retention: retention,
 	}
+	a.monitor = monitor.New("redis", func(ctx context.Context) error {
+		if err := client.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		return nil
+	}, logger)
+	a.monitor.Start()
 
-	return &Adapter{
-		client: client,
-	}, nil
+	return a, nil
+}
+
+// Health returns the state of the connection to Redis
+func (a *Adapter) Health() models.DependencyHealth {
+	return a.monitor.Health()
 }
 
 // SetLatestPrice sets the latest price for a symbol from an exchange
@@ -57,7 +73,7 @@ func (a *Adapter) SetLatestPrice(ctx context.Context, update models.PriceUpdate)
 	}
 
 	// Set with TTL
-	if err := a.client.Set(ctx, key, data, 2*time.Minute).Err(); err != nil {
+	if err := a.client.Set(ctx, key, data, latestPriceTTL).Err(); err != nil {
 		return err
 	}
 
@@ -77,8 +93,8 @@ func (a *Adapter) SetLatestPrice(ctx context.Context, update models.PriceUpdate)
 		return err
 	}
 
-	// Remove old entries (older than 2 minutes)
-	cutoff := time.Now().Add(-2 * time.Minute).UnixMilli()
+	// Remove entries older than the retention
+	cutoff := time.Now().Add(-a.retention).UnixMilli()
 	a.client.ZRemRangeByScore(ctx, historyKey, "0", fmt.Sprintf("%d", cutoff))
 
 	return nil
@@ -139,17 +155,15 @@ func (a *Adapter) GetLatestPrices(ctx context.Context, symbol string) ([]*models
 	return prices, nil
 }
 
-// GetPriceHistory gets price history for aggregation (last minute)
-func (a *Adapter) GetPriceHistory(ctx context.Context, symbol, exchange string, duration time.Duration) ([]models.PriceUpdate, error) {
+// GetPriceHistory gets the updates received within [from, to) for aggregation
+func (a *Adapter) GetPriceHistory(ctx context.Context, symbol, exchange string, from, to time.Time) ([]models.PriceUpdate, error) {
 	key := fmt.Sprintf("history:%s:%s", exchange, symbol)
 
-	now := time.Now()
-	start := now.Add(-duration)
-
-	// Use sorted set to get price history within time range
+	// Use sorted set to get price history within time range; the end is exclusive
+	// so a tick on a window boundary belongs only to the window it opens
 	values, err := a.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
-		Min: fmt.Sprintf("%d", start.UnixMilli()),
-		Max: fmt.Sprintf("%d", now.UnixMilli()),
+		Min: fmt.Sprintf("%d", from.UnixMilli()),
+		Max: fmt.Sprintf("(%d", to.UnixMilli()),
 	}).Result()
 	if err != nil {
 		return nil, err
@@ -205,5 +219,6 @@ func (a *Adapter) CleanupOldData(ctx context.Context, maxAge time.Duration) erro
 
 // Close closes the cache connection
 func (a *Adapter) Close() error {
+	a.monitor.Stop()
 	return a.client.Close()
 }
@@ -0,0 +1,78 @@
+package live
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"marketflow/internal/config"
+)
+
+const (
+	defaultInitialDelay = 500 * time.Millisecond
+	defaultMaxDelay     = 30 * time.Second
+	defaultMultiplier   = 2.0
+	defaultJitter       = 0.2
+)
+
+// backoff computes exponential reconnect delays with jitter
+type backoff struct {
+	initial     time.Duration
+	max         time.Duration
+	multiplier  float64
+	jitter      float64
+	maxAttempts int
+}
+
+func newBackoff(cfg config.ReconnectConfig) backoff {
+	b := backoff{
+		initial:     cfg.InitialDelay.Std(),
+		max:         cfg.MaxDelay.Std(),
+		multiplier:  cfg.Multiplier,
+		jitter:      cfg.Jitter,
+		maxAttempts: cfg.MaxAttempts,
+	}
+
+	if b.initial <= 0 {
+		b.initial = defaultInitialDelay
+	}
+	if b.max <= 0 {
+		b.max = defaultMaxDelay
+	}
+	if b.max < b.initial {
+		b.max = b.initial
+	}
+	if b.multiplier < 1 {
+		b.multiplier = defaultMultiplier
+	}
+	if b.jitter < 0 || b.jitter > 1 {
+		b.jitter = defaultJitter
+	}
+
+	return b
+}
+
+// delay returns the wait before the given attempt (starting at 1)
+func (b backoff) delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := float64(b.initial) * math.Pow(b.multiplier, float64(attempt-1))
+	if d > float64(b.max) {
+		d = float64(b.max)
+	}
+
+	// Spread reconnects by ±jitter so upstreams don't retry in lockstep
+	d *= 1 + b.jitter*(2*rand.Float64()-1)
+	if d > float64(b.max) {
+		d = float64(b.max)
+	}
+
+	return time.Duration(d)
+}
+
+// exhausted reports whether no more attempts should be made
+func (b backoff) exhausted(attempt int) bool {
+	return b.maxAttempts > 0 && attempt >= b.maxAttempts
+}
@@ -3,58 +3,148 @@ package live
 import (
 	"bufio"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"marketflow/internal/application/ports"
+	"marketflow/internal/concurrency"
 	"marketflow/internal/config"
 	"marketflow/internal/domain/models"
 )
 
+const defaultStaleTimeout = 15 * time.Second
+
+var (
+	errConnectionClosed = errors.New("connection closed by upstream")
+	errStaleFeed        = errors.New("feed stale")
+)
+
 // Adapter implements the ExchangePort interface for live exchanges
 type Adapter struct {
-	exchanges []config.ExchangeConfig
-	connected bool
+	upstreams    []*upstream
+	backoff      backoff
+	staleTimeout time.Duration
+	overflow     config.OverflowConfig
+	counters     *concurrency.OverflowCounters
+	queue        *concurrency.OverflowQueue
+	mu           sync.RWMutex
+	ctx          context.Context // set while the adapter is started
+	cancel       context.CancelFunc
+	changes      chan struct{}
+
+	healthMu sync.Mutex
+	health   models.DependencyHealth
+}
+
+// upstream tracks the connection state of a single exchange
+type upstream struct {
+	name          string
+	cfg           config.ExchangeConfig
+	symbols       map[string]struct{}
+	decode        decodeFunc
+	decodeErr     error
+	paused        bool
+	session       int // bumped on every launch or halt so stale goroutines can't write state
+	cancel        context.CancelFunc
+	state         models.ConnectionState
+	lastError     error
+	reconnects    int
+	staleCount    int
+	connectedAt   time.Time
+	lastMessageAt atomic.Int64 // unix nanoseconds; written on every message without taking a.mu
+	nextRetryAt   time.Time
 }
 
 // New creates a new live exchange adapter
 func New(cfg config.ExchangesConfig) ports.ExchangePort {
-	exchanges := []config.ExchangeConfig{
-		cfg.Exchange1,
-		cfg.Exchange2,
-		cfg.Exchange3,
+	upstreams := make([]*upstream, 0, len(cfg.Live))
+	for i, exchange := range cfg.Live {
+		upstreams = append(upstreams, newUpstream(i, exchange))
 	}
 
+	staleTimeout := cfg.StaleTimeout.Std()
+	if staleTimeout <= 0 {
+		staleTimeout = defaultStaleTimeout
+	}
+
+	overflow := cfg.Backpressure.Live
+	counters := concurrency.NewOverflowCounters()
+
 	return &Adapter{
-		exchanges: exchanges,
-		connected: false,
+		upstreams:    upstreams,
+		backoff:      newBackoff(cfg.Reconnect),
+		staleTimeout: staleTimeout,
+		overflow:     overflow,
+		counters:     counters,
+		queue:        concurrency.NewOverflowQueue(concurrency.OverflowPolicy(overflow.Policy), overflow.BufferSize, counters),
+		changes:      make(chan struct{}, 1),
+		health: models.DependencyHealth{
+			Name:  "exchange",
+			State: models.DependencyConnecting,
+			Since: time.Now(),
+		},
 	}
 }
 
 // Start begins data collection
 func (a *Adapter) Start(ctx context.Context) (<-chan models.PriceUpdate, error) {
-	updateCh := make(chan models.PriceUpdate, 1000)
+	queue := concurrency.NewOverflowQueue(concurrency.OverflowPolicy(a.overflow.Policy), a.overflow.BufferSize, a.counters)
 
-	for i, exchange := range a.exchanges {
-		exchangeName := fmt.Sprintf("exchange%d", i+1)
-		go a.connectToExchange(ctx, exchange, exchangeName, updateCh)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cancel != nil {
+		a.cancel()
 	}
+	a.ctx, a.cancel = context.WithCancel(ctx)
+	a.queue = queue
+	queue.Start(a.ctx)
 
-	a.connected = true
-	return updateCh, nil
+	for _, up := range a.upstreams {
+		a.launch(up)
+	}
+
+	return queue.Out(), nil
 }
 
 // Stop stops data collection
 func (a *Adapter) Stop() error {
-	a.connected = false
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+	a.ctx = nil
+
+	for _, up := range a.upstreams {
+		a.halt(up)
+		if up.cfg.IsEnabled() && !up.paused {
+			up.state = models.ConnectionStateDisconnected
+		}
+	}
+
 	return nil
 }
 
-// IsConnected returns connection status
+// IsConnected reports whether at least one upstream is connected and sending data
 func (a *Adapter) IsConnected() bool {
-	return a.connected
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	now := time.Now()
+	for _, up := range a.upstreams {
+		if a.isFresh(up, now) {
+			return true
+		}
+	}
+	return false
 }
 
 // GetName returns the exchange name
@@ -62,51 +152,438 @@ func (a *Adapter) GetName() string {
 	return "live"
 }
 
-func (a *Adapter) connectToExchange(ctx context.Context, cfg config.ExchangeConfig, exchangeName string, updateCh chan<- models.PriceUpdate) {
-	for {
+// Health reports the feed as up while at least one upstream is sending data,
+// with the error of the first failing upstream otherwise
+func (a *Adapter) Health() models.DependencyHealth {
+	now := time.Now()
+	state := models.DependencyDown
+	lastError := ""
+
+	a.mu.RLock()
+	for _, up := range a.upstreams {
+		if a.isFresh(up, now) {
+			state = models.DependencyUp
+			lastError = ""
+			break
+		}
+		if up.state == models.ConnectionStateConnecting {
+			state = models.DependencyConnecting
+		}
+		if lastError == "" && up.lastError != nil {
+			lastError = up.name + ": " + up.lastError.Error()
+		}
+	}
+	a.mu.RUnlock()
+
+	a.healthMu.Lock()
+	defer a.healthMu.Unlock()
+
+	if state != a.health.State {
+		a.health.State = state
+		a.health.Since = now
+	}
+	a.health.LastCheck = now
+	a.health.LastError = lastError
+	return a.health
+}
+
+// GetExchanges returns the names of the enabled upstream exchanges
+func (a *Adapter) GetExchanges() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	names := make([]string, 0, len(a.upstreams))
+	for _, up := range a.upstreams {
+		if up.cfg.IsEnabled() {
+			names = append(names, up.name)
+		}
+	}
+	return names
+}
+
+// GetStatus returns the connection status of every upstream exchange
+func (a *Adapter) GetStatus() []models.ExchangeStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	statuses := make([]models.ExchangeStatus, 0, len(a.upstreams))
+	for _, up := range a.upstreams {
+		status := models.ExchangeStatus{
+			Name:       up.name,
+			Address:    address(up.cfg),
+			State:      up.state,
+			Reconnects: up.reconnects,
+			StaleCount: up.staleCount,
+		}
+		if up.lastError != nil {
+			status.LastError = up.lastError.Error()
+		}
+		if lastMessageAt := up.lastMessage(); !lastMessageAt.IsZero() {
+			status.LastMessageAt = &lastMessageAt
+		}
+		if !up.nextRetryAt.IsZero() {
+			nextRetryAt := up.nextRetryAt
+			status.NextRetryAt = &nextRetryAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// AddExchange registers a new upstream and connects it if the adapter is running
+func (a *Adapter) AddExchange(cfg config.ExchangeConfig) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	up := newUpstream(len(a.upstreams), cfg)
+	if a.find(up.name) != nil {
+		return fmt.Errorf("%w: %s", ports.ErrExchangeExists, up.name)
+	}
+
+	a.upstreams = append(a.upstreams, up)
+	a.launch(up)
+	return nil
+}
+
+// RemoveExchange disconnects an upstream and forgets it
+func (a *Adapter) RemoveExchange(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i, up := range a.upstreams {
+		if up.name == name {
+			a.halt(up)
+			a.upstreams = append(a.upstreams[:i], a.upstreams[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ports.ErrExchangeNotFound, name)
+}
+
+// PauseExchange disconnects an upstream but keeps it registered
+func (a *Adapter) PauseExchange(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	up := a.find(name)
+	if up == nil {
+		return fmt.Errorf("%w: %s", ports.ErrExchangeNotFound, name)
+	}
+
+	a.halt(up)
+	up.paused = true
+	up.state = models.ConnectionStatePaused
+	return nil
+}
+
+// ResumeExchange reconnects a paused or disabled upstream
+func (a *Adapter) ResumeExchange(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	up := a.find(name)
+	if up == nil {
+		return fmt.Errorf("%w: %s", ports.ErrExchangeNotFound, name)
+	}
+	if !up.paused && up.cfg.IsEnabled() {
+		return nil
+	}
+
+	enabled := true
+	up.cfg.Enabled = &enabled
+	up.paused = false
+	up.state = models.ConnectionStateDisconnected
+	a.launch(up)
+	return nil
+}
+
+// UpdateExchange replaces the configuration of an upstream and reconnects it
+func (a *Adapter) UpdateExchange(cfg config.ExchangeConfig) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i, old := range a.upstreams {
+		if old.name == cfg.Name {
+			a.halt(old)
+
+			up := newUpstream(i, cfg)
+			up.paused = old.paused
+			a.upstreams[i] = up
+			a.launch(up)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ports.ErrExchangeNotFound, cfg.Name)
+}
+
+// launch starts the connection loop of an upstream; callers must hold a.mu
+func (a *Adapter) launch(up *upstream) {
+	if !up.cfg.IsEnabled() {
+		up.state = models.ConnectionStateDisabled
+		return
+	}
+	if up.paused {
+		up.state = models.ConnectionStatePaused
+		return
+	}
+	if a.ctx == nil {
+		return
+	}
+
+	a.halt(up)
+	up.state = models.ConnectionStateConnecting
+	up.lastError = nil
+	up.reconnects = 0
+	up.staleCount = 0
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	up.cancel = cancel
+	go a.connectToExchange(ctx, up, up.session, a.queue)
+}
+
+// halt stops the connection loop of an upstream; callers must hold a.mu
+func (a *Adapter) halt(up *upstream) {
+	if up.cancel != nil {
+		up.cancel()
+		up.cancel = nil
+	}
+	up.session++
+	up.nextRetryAt = time.Time{}
+}
+
+// find returns the upstream with the given name; callers must hold a.mu
+func (a *Adapter) find(name string) *upstream {
+	for _, up := range a.upstreams {
+		if up.name == name {
+			return up
+		}
+	}
+	return nil
+}
+
+// FeedChanges returns a channel that receives a value after a connection loop
+// changes the state of an upstream
+func (a *Adapter) FeedChanges() <-chan struct{} {
+	return a.changes
+}
+
+// GetOverflowStats returns backpressure counters for the live feeds
+func (a *Adapter) GetOverflowStats() models.OverflowStats {
+	a.mu.RLock()
+	queue := a.queue
+	a.mu.RUnlock()
+
+	return queue.Stats(a.GetName())
+}
+
+func (a *Adapter) connectToExchange(ctx context.Context, up *upstream, session int, queue *concurrency.OverflowQueue) {
+	if up.decodeErr != nil {
+		a.setState(up, session, models.ConnectionStateFailed, up.decodeErr)
+		return
+	}
+
+	attempt := 0
+
+	for ctx.Err() == nil {
+		a.setState(up, session, models.ConnectionStateConnecting, nil)
+
+		received, err := a.handleExchangeConnection(ctx, up, session, queue)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errConnectionClosed
+		}
+
+		// A connection that delivered data resets the backoff sequence
+		if received {
+			attempt = 0
+		}
+		attempt++
+
+		if a.backoff.exhausted(attempt) {
+			a.setState(up, session, models.ConnectionStateFailed, err)
+			return
+		}
+
+		delay := a.backoff.delay(attempt)
+		a.update(up, session, func() {
+			up.state = models.ConnectionStateBackingOff
+			up.lastError = err
+			up.nextRetryAt = time.Now().Add(delay)
+		})
+
+		timer := time.NewTimer(delay)
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			return
-		default:
-			if err := a.handleExchangeConnection(ctx, cfg, exchangeName, updateCh); err != nil {
-				// Wait before reconnecting
-				time.Sleep(5 * time.Second)
-			}
+		case <-timer.C:
 		}
+
+		a.update(up, session, func() {
+			up.reconnects++
+			up.nextRetryAt = time.Time{}
+		})
 	}
 }
 
-func (a *Adapter) handleExchangeConnection(ctx context.Context, cfg config.ExchangeConfig, exchangeName string, updateCh chan<- models.PriceUpdate) error {
-	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
-	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+func (a *Adapter) handleExchangeConnection(ctx context.Context, up *upstream, session int, queue *concurrency.OverflowQueue) (bool, error) {
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", address(up.cfg))
 	if err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", exchangeName, err)
+		return false, fmt.Errorf("failed to connect to %s: %w", up.name, err)
 	}
 	defer conn.Close()
 
+	a.update(up, session, func() {
+		up.state = models.ConnectionStateConnected
+		up.connectedAt = time.Now()
+	})
+
+	// Unblock the scanner when the adapter is stopped
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	received := false
 	scanner := bufio.NewScanner(conn)
-	for scanner.Scan() {
+	for {
+		// An upstream that keeps the socket open but stops sending is treated as dead
+		conn.SetReadDeadline(time.Now().Add(a.staleTimeout))
+		if !scanner.Scan() {
+			break
+		}
+
 		select {
 		case <-ctx.Done():
-			return nil
+			return received, nil
 		default:
 			var update models.PriceUpdate
-			if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			if err := up.decode(scanner.Bytes(), &update); err != nil {
+				continue
+			}
+			if !up.accepts(update.Symbol) {
 				continue
 			}
 
-			update.Exchange = exchangeName
+			update.Exchange = up.name
 			update.ReceivedAt = time.Now()
+			received = true
+
+			up.lastMessageAt.Store(update.ReceivedAt.UnixNano())
 
-			select {
-			case updateCh <- update:
-			case <-ctx.Done():
-				return nil
-			default:
-				// Channel is full, skip this update
+			if !queue.Push(ctx, update) {
+				return received, nil
 			}
 		}
 	}
 
-	return scanner.Err()
+	err = scanner.Err()
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() && ctx.Err() == nil {
+		a.update(up, session, func() {
+			up.state = models.ConnectionStateStale
+			up.staleCount++
+		})
+		return received, fmt.Errorf("%w: no data from %s for %s", errStaleFeed, up.name, a.staleTimeout)
+	}
+
+	return received, err
+}
+
+// isFresh reports whether an upstream is connected and has sent data within the stale timeout
+func (a *Adapter) isFresh(up *upstream, now time.Time) bool {
+	if up.state != models.ConnectionStateConnected {
+		return false
+	}
+
+	last := up.lastMessage()
+	if up.connectedAt.After(last) {
+		last = up.connectedAt
+	}
+	return now.Sub(last) < a.staleTimeout
+}
+
+func newUpstream(index int, cfg config.ExchangeConfig) *upstream {
+	name := cfg.Name
+	if name == "" {
+		name = fmt.Sprintf("exchange%d", index+1)
+	}
+
+	var symbols map[string]struct{}
+	if len(cfg.Symbols) > 0 {
+		symbols = make(map[string]struct{}, len(cfg.Symbols))
+		for _, symbol := range cfg.Symbols {
+			symbols[symbol] = struct{}{}
+		}
+	}
+
+	decode, err := decoderFor(cfg.Protocol)
+
+	state := models.ConnectionStateDisconnected
+	if !cfg.IsEnabled() {
+		state = models.ConnectionStateDisabled
+	}
+
+	return &upstream{
+		name:      name,
+		cfg:       cfg,
+		symbols:   symbols,
+		decode:    decode,
+		decodeErr: err,
+		state:     state,
+	}
+}
+
+// accepts reports whether the upstream's symbol filter lets a symbol through
+func (up *upstream) accepts(symbol string) bool {
+	if up.symbols == nil {
+		return true
+	}
+	_, ok := up.symbols[symbol]
+	return ok
+}
+
+// lastMessage returns when the upstream last delivered an update, or the zero time
+func (up *upstream) lastMessage() time.Time {
+	nanos := up.lastMessageAt.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// update applies fn to an upstream unless its connection loop has been replaced
+// or halted; it is for state transitions, not for every message
+func (a *Adapter) update(up *upstream, session int, fn func()) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if up.session != session {
+		return false
+	}
+
+	state := up.state
+	fn()
+	if up.state != state {
+		select {
+		case a.changes <- struct{}{}:
+		default: // a change is already signalled
+		}
+	}
+	return true
+}
+
+func (a *Adapter) setState(up *upstream, session int, state models.ConnectionState, err error) {
+	a.update(up, session, func() {
+		up.state = state
+		if err != nil {
+			up.lastError = err
+		}
+	})
+}
+
+func address(cfg config.ExchangeConfig) string {
+	return net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
 }
@@ -0,0 +1,34 @@
+package live
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"marketflow/internal/domain/models"
+)
+
+// defaultProtocol is newline-delimited JSON, as sent by the reference exchanges
+const defaultProtocol = "json"
+
+// decodeFunc parses one line received from an upstream into a price update
+type decodeFunc func(line []byte, update *models.PriceUpdate) error
+
+var decoders = map[string]decodeFunc{
+	"json": decodeJSON,
+}
+
+func decoderFor(protocol string) (decodeFunc, error) {
+	if protocol == "" {
+		protocol = defaultProtocol
+	}
+
+	decode, ok := decoders[protocol]
+	if !ok {
+		return nil, fmt.Errorf("unsupported protocol %q", protocol)
+	}
+	return decode, nil
+}
+
+func decodeJSON(line []byte, update *models.PriceUpdate) error {
+	return json.Unmarshal(line, update)
+}
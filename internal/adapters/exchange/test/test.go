@@ -2,60 +2,90 @@ package test
 
 import (
 	"context"
+	"math"
 	"math/rand"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"marketflow/internal/application/ports"
+	"marketflow/internal/concurrency"
+	"marketflow/internal/config"
 	"marketflow/internal/domain/models"
+	"marketflow/internal/domain/symbols"
 )
 
+var exchanges = []string{"test-exchange1", "test-exchange2", "test-exchange3"}
+
+// basePrices seeds the generator for well-known symbols
+var basePrices = map[string]float64{
+	"BTCUSDT":  99000.0,
+	"DOGEUSDT": 0.30,
+	"TONUSDT":  3.90,
+	"SOLUSDT":  200.0,
+	"ETHUSDT":  3000.0,
+}
+
+// defaultBasePrice seeds symbols without a known base price
+const defaultBasePrice = 100.0
+
 // Adapter implements the ExchangePort interface for test data
 type Adapter struct {
-	connected bool
+	connected atomic.Bool // read by health checks and failover while Start and Stop run
+	symbols   *symbols.Registry
+	overflow  config.OverflowConfig
+	counters  *concurrency.OverflowCounters
+	queue     *concurrency.OverflowQueue
+	mu        sync.RWMutex
+
+	healthMu sync.Mutex
+	health   models.DependencyHealth
 }
 
 // New creates a new test exchange adapter
-func New() ports.ExchangePort {
+func New(overflow config.OverflowConfig, registry *symbols.Registry) ports.ExchangePort {
+	counters := concurrency.NewOverflowCounters()
+
 	return &Adapter{
-		connected: false,
+		symbols:  registry,
+		overflow: overflow,
+		counters: counters,
+		queue:    concurrency.NewOverflowQueue(concurrency.OverflowPolicy(overflow.Policy), overflow.BufferSize, counters),
+		health: models.DependencyHealth{
+			Name:  "exchange",
+			State: models.DependencyDown,
+			Since: time.Now(),
+		},
 	}
 }
 
 // Start begins data collection
 func (a *Adapter) Start(ctx context.Context) (<-chan models.PriceUpdate, error) {
-	updateCh := make(chan models.PriceUpdate, 1000)
-
-	symbols := []string{"BTCUSDT", "DOGEUSDT", "TONUSDT", "SOLUSDT", "ETHUSDT"}
+	queue := concurrency.NewOverflowQueue(concurrency.OverflowPolicy(a.overflow.Policy), a.overflow.BufferSize, a.counters)
+	queue.Start(ctx)
 
-	// Base prices for each symbol
-	basePrices := map[string]float64{
-		"BTCUSDT":  99000.0,
-		"DOGEUSDT": 0.30,
-		"TONUSDT":  3.90,
-		"SOLUSDT":  200.0,
-		"ETHUSDT":  3000.0,
-	}
-
-	exchanges := []string{"test-exchange1", "test-exchange2", "test-exchange3"}
+	a.mu.Lock()
+	a.queue = queue
+	a.mu.Unlock()
 
 	// Start generators for each exchange
 	for _, exchange := range exchanges {
-		go a.generateData(ctx, symbols, basePrices, exchange, updateCh)
+		go a.generateData(ctx, exchange, queue)
 	}
 
-	a.connected = true
-	return updateCh, nil
+	a.connected.Store(true)
+	return queue.Out(), nil
 }
 
 // Stop stops data collection
 func (a *Adapter) Stop() error {
-	a.connected = false
+	a.connected.Store(false)
 	return nil
 }
 
 // IsConnected returns connection status
 func (a *Adapter) IsConnected() bool {
-	return a.connected
+	return a.connected.Load()
 }
 
 // GetName returns the exchange name
@@ -63,7 +93,59 @@ func (a *Adapter) GetName() string {
 	return "test"
 }
 
-func (a *Adapter) generateData(ctx context.Context, symbols []string, basePrices map[string]float64, exchange string, updateCh chan<- models.PriceUpdate) {
+// Health reports the generator as up while it is running
+func (a *Adapter) Health() models.DependencyHealth {
+	now := time.Now()
+	state := models.DependencyDown
+	if a.connected.Load() {
+		state = models.DependencyUp
+	}
+
+	a.healthMu.Lock()
+	defer a.healthMu.Unlock()
+
+	if state != a.health.State {
+		a.health.State = state
+		a.health.Since = now
+	}
+	a.health.LastCheck = now
+	return a.health
+}
+
+// GetExchanges returns the names of the simulated exchanges
+func (a *Adapter) GetExchanges() []string {
+	names := make([]string, len(exchanges))
+	copy(names, exchanges)
+	return names
+}
+
+// GetStatus returns the status of every simulated exchange
+func (a *Adapter) GetStatus() []models.ExchangeStatus {
+	state := models.ConnectionStateDisconnected
+	if a.connected.Load() {
+		state = models.ConnectionStateConnected
+	}
+
+	statuses := make([]models.ExchangeStatus, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		statuses = append(statuses, models.ExchangeStatus{
+			Name:  exchange,
+			State: state,
+		})
+	}
+	return statuses
+}
+
+// GetOverflowStats returns backpressure counters for the generated feeds
+func (a *Adapter) GetOverflowStats() models.OverflowStats {
+	a.mu.RLock()
+	queue := a.queue
+	a.mu.RUnlock()
+
+	return queue.Stats(a.GetName())
+}
+
+func (a *Adapter) generateData(ctx context.Context, exchange string, queue *concurrency.OverflowQueue) {
 	ticker := time.NewTicker(100 * time.Millisecond) // Generate data every 100ms
 	defer ticker.Stop()
 
@@ -72,27 +154,28 @@ func (a *Adapter) generateData(ctx context.Context, symbols []string, basePrices
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			for _, symbol := range symbols {
-				basePrice := basePrices[symbol]
+			// Symbols are read on every tick so registry changes apply immediately
+			for _, symbol := range a.symbols.List() {
+				basePrice, ok := basePrices[symbol.Name]
+				if !ok {
+					basePrice = defaultBasePrice
+				}
 
 				// Add some random variation (±2%)
 				variation := (rand.Float64() - 0.5) * 0.04 // -2% to +2%
-				price := basePrice * (1 + variation)
+				scale := math.Pow10(symbol.Precision)
+				price := math.Round(basePrice*(1+variation)*scale) / scale
 
 				update := models.PriceUpdate{
-					Symbol:     symbol,
+					Symbol:     symbol.Name,
 					Price:      price,
 					Timestamp:  time.Now().UnixMilli(),
 					Exchange:   exchange,
 					ReceivedAt: time.Now(),
 				}
 
-				select {
-				case updateCh <- update:
-				case <-ctx.Done():
+				if !queue.Push(ctx, update) {
 					return
-				default:
-					// Channel is full, skip this update
 				}
 			}
 		}
@@ -0,0 +1,135 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+const (
+	// checkInterval is how often a backing service is pinged
+	checkInterval = 5 * time.Second
+
+	// checkTimeout bounds a single ping
+	checkTimeout = 3 * time.Second
+)
+
+// Monitor pings a backing service in the background and tracks whether it is
+// reachable, so adapters can start while it is down and report its state
+type Monitor struct {
+	ping   func(ctx context.Context) error
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	health models.DependencyHealth
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a monitor for the named service; ping must return nil when the service is usable
+func New(name string, ping func(ctx context.Context) error, logger *slog.Logger) *Monitor {
+	return &Monitor{
+		ping:   ping,
+		logger: logger.With("dependency", name),
+		health: models.DependencyHealth{
+			Name:  name,
+			State: models.DependencyConnecting,
+			Since: time.Now(),
+		},
+	}
+}
+
+// Start checks the service once and keeps checking it in the background until
+// Stop; it returns the result of the first check
+func (m *Monitor) Start() error {
+	err := m.Check(context.Background())
+	if err != nil {
+		m.logger.Warn("Dependency unavailable, starting degraded and retrying in the background", "error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.run(ctx)
+
+	return err
+}
+
+// Stop stops the background checks
+func (m *Monitor) Stop() {
+	if m.cancel == nil {
+		return
+	}
+	m.cancel()
+	<-m.done
+}
+
+// Check pings the service now and records the result
+func (m *Monitor) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	started := time.Now()
+	err := m.ping(ctx)
+	now := time.Now()
+
+	state := models.DependencyUp
+	if err != nil {
+		state = models.DependencyDown
+	}
+
+	m.mu.Lock()
+	previous := m.health.State
+	if state != previous {
+		m.health.State = state
+		m.health.Since = now
+	}
+	m.health.LastCheck = now
+	m.health.LatencyMs = float64(now.Sub(started).Microseconds()) / 1000
+	m.health.LastError = ""
+	if err != nil {
+		m.health.LastError = err.Error()
+	}
+	m.mu.Unlock()
+
+	switch {
+	case state == models.DependencyUp && previous == models.DependencyDown:
+		m.logger.Info("Dependency reconnected")
+	case state == models.DependencyDown && previous == models.DependencyUp:
+		m.logger.Error("Dependency connection lost", "error", err)
+	}
+
+	return err
+}
+
+// Health returns the last recorded state of the service
+func (m *Monitor) Health() models.DependencyHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.health
+}
+
+// Up reports whether the last check succeeded
+func (m *Monitor) Up() bool {
+	return m.Health().State == models.DependencyUp
+}
+
+func (m *Monitor) run(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
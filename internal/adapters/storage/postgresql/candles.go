@@ -0,0 +1,148 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+const candleColumns = `pair_name, exchange, interval, open_time, close_time, open_price, high_price, low_price, close_price, tick_count, first_tick_at, last_tick_at`
+
+// candleStaging receives candles before they are upserted into candles
+var candleStaging = stagingTable{
+	name: "candles_staging",
+	columns: []string{
+		"pair_name VARCHAR(20)", "exchange VARCHAR(50)", "interval VARCHAR(10)",
+		"open_time TIMESTAMP WITH TIME ZONE", "close_time TIMESTAMP WITH TIME ZONE",
+		"open_price DECIMAL(20, 8)", "high_price DECIMAL(20, 8)", "low_price DECIMAL(20, 8)", "close_price DECIMAL(20, 8)",
+		"tick_count BIGINT", "first_tick_at TIMESTAMP WITH TIME ZONE", "last_tick_at TIMESTAMP WITH TIME ZONE",
+	},
+}
+
+// SaveCandles saves completed OHLC candles; saving a candle again replaces it.
+// Candles are copied into a staging table and upserted with one statement.
+func (a *Adapter) SaveCandles(ctx context.Context, candles []models.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, 0, len(candles))
+	for _, c := range candles {
+		rows = append(rows, []interface{}{c.PairName, c.Exchange, c.Interval, c.OpenTime, c.CloseTime,
+			c.Open, c.High, c.Low, c.Close, c.TickCount, c.FirstTickAt, c.LastTickAt})
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := copyIn(ctx, tx, candleStaging, rows); err != nil {
+		return err
+	}
+
+	// The last copy of a candle in the batch wins
+	_, err = tx.ExecContext(ctx, `INSERT INTO candles (`+candleColumns+`)
+			  SELECT DISTINCT ON (pair_name, exchange, interval, open_time) `+candleColumns+`
+			  FROM candles_staging
+			  ORDER BY pair_name, exchange, interval, open_time, seq DESC
+			  ON CONFLICT (pair_name, exchange, interval, open_time) DO UPDATE SET
+				close_time = EXCLUDED.close_time,
+				open_price = EXCLUDED.open_price,
+				high_price = EXCLUDED.high_price,
+				low_price = EXCLUDED.low_price,
+				close_price = EXCLUDED.close_price,
+				tick_count = EXCLUDED.tick_count,
+				first_tick_at = EXCLUDED.first_tick_at,
+				last_tick_at = EXCLUDED.last_tick_at`)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AmendCandles folds partial candles into the persisted candles of the same
+// window, creating them if missing: the open and close follow the earliest and
+// latest tick of both
+func (a *Adapter) AmendCandles(ctx context.Context, candles []models.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO candles (` + candleColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			  ON CONFLICT (pair_name, exchange, interval, open_time) DO UPDATE SET
+				open_price = CASE WHEN EXCLUDED.first_tick_at < candles.first_tick_at
+					THEN EXCLUDED.open_price ELSE candles.open_price END,
+				high_price = GREATEST(candles.high_price, EXCLUDED.high_price),
+				low_price = LEAST(candles.low_price, EXCLUDED.low_price),
+				close_price = CASE WHEN EXCLUDED.last_tick_at >= candles.last_tick_at
+					THEN EXCLUDED.close_price ELSE candles.close_price END,
+				tick_count = candles.tick_count + EXCLUDED.tick_count,
+				first_tick_at = LEAST(candles.first_tick_at, EXCLUDED.first_tick_at),
+				last_tick_at = GREATEST(candles.last_tick_at, EXCLUDED.last_tick_at)`
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range candles {
+		_, err := stmt.ExecContext(ctx, c.PairName, c.Exchange, c.Interval, c.OpenTime, c.CloseTime,
+			c.Open, c.High, c.Low, c.Close, c.TickCount, c.FirstTickAt, c.LastTickAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetCandles retrieves candles of one interval opened within [from, to), oldest first
+func (a *Adapter) GetCandles(ctx context.Context, symbol, exchange, interval string, from, to time.Time) ([]models.Candle, error) {
+	var query string
+	var args []interface{}
+
+	if exchange != "" {
+		query = `SELECT ` + candleColumns + `
+				 FROM candles
+				 WHERE pair_name = $1 AND exchange = $2 AND interval = $3 AND open_time >= $4 AND open_time < $5
+				 ORDER BY open_time, exchange`
+		args = []interface{}{symbol, exchange, interval, from, to}
+	} else {
+		query = `SELECT ` + candleColumns + `
+				 FROM candles
+				 WHERE pair_name = $1 AND interval = $2 AND open_time >= $3 AND open_time < $4
+				 ORDER BY open_time, exchange`
+		args = []interface{}{symbol, interval, from, to}
+	}
+
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candles := []models.Candle{}
+	for rows.Next() {
+		var c models.Candle
+		err := rows.Scan(&c.PairName, &c.Exchange, &c.Interval, &c.OpenTime, &c.CloseTime,
+			&c.Open, &c.High, &c.Low, &c.Close, &c.TickCount, &c.FirstTickAt, &c.LastTickAt)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, c)
+	}
+
+	return candles, rows.Err()
+}
@@ -0,0 +1,51 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// stagingTable describes a temporary table rows are copied into before being
+// upserted into their target table in a single statement
+type stagingTable struct {
+	name    string
+	columns []string // column definitions, in copy order
+}
+
+// columnNames returns the names of the staging columns, in copy order
+func (t stagingTable) columnNames() []string {
+	names := make([]string, 0, len(t.columns))
+	for _, column := range t.columns {
+		names = append(names, strings.Fields(column)[0])
+	}
+	return names
+}
+
+// copyIn creates the staging table inside tx and streams rows into it with
+// COPY; the table is dropped when tx ends. Each row is prefixed with its
+// position, in a seq column, so later rows can win over earlier duplicates.
+func copyIn(ctx context.Context, tx *sql.Tx, table stagingTable, rows [][]interface{}) error {
+	definition := "seq BIGINT NOT NULL, " + strings.Join(table.columns, ", ")
+	if _, err := tx.ExecContext(ctx, `CREATE TEMP TABLE `+table.name+` (`+definition+`) ON COMMIT DROP`); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table.name, append([]string{"seq"}, table.columnNames()...)...))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, row := range rows {
+		if _, err := stmt.ExecContext(ctx, append([]interface{}{i}, row...)...); err != nil {
+			return err
+		}
+	}
+
+	// Flush the buffered rows
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
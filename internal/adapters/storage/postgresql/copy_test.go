@@ -0,0 +1,132 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+// benchDSNEnv names the variable holding the DSN of a disposable database the
+// benchmarks may migrate and write to; they are skipped when it is unset
+const benchDSNEnv = "MARKETFLOW_BENCH_POSTGRES_DSN"
+
+const benchExchange = "bench"
+
+func openBenchDB(b *testing.B) *sql.DB {
+	dsn := os.Getenv(benchDSNEnv)
+	if dsn == "" {
+		b.Skipf("%s is not set", benchDSNEnv)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		b.Fatal(err)
+	}
+
+	cleanup := func() {
+		if _, err := db.ExecContext(ctx, `DELETE FROM market_data WHERE exchange = $1`, benchExchange); err != nil {
+			b.Fatal(err)
+		}
+	}
+	cleanup()
+	b.Cleanup(cleanup)
+	return db
+}
+
+// benchRows returns size windows, half of them already stored by the
+// previous iteration so the upsert both inserts and updates
+func benchRows(size, iteration int) []models.AggregatedData {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(iteration*size/2) * time.Minute)
+	rows := make([]models.AggregatedData, 0, size)
+	for i := 0; i < size; i++ {
+		at := start.Add(time.Duration(i) * time.Minute)
+		rows = append(rows, models.AggregatedData{
+			PairName:     "BTCUSDT",
+			Exchange:     benchExchange,
+			Timestamp:    at,
+			WindowStart:  at,
+			WindowEnd:    at.Add(time.Minute),
+			Interval:     "1m",
+			AveragePrice: 100 + float64(i%17),
+			MinPrice:     99,
+			MaxPrice:     120,
+			TickCount:    60,
+		})
+	}
+	return rows
+}
+
+// insertRows is the per-row upsert SaveAggregatedData ran before COPY
+func insertRows(ctx context.Context, db *sql.DB, data []models.AggregatedData) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO market_data (pair_name, exchange, timestamp, window_start, window_end, interval, average_price, min_price, max_price, tick_count)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			  ON CONFLICT (pair_name, exchange, window_start, interval) DO UPDATE SET
+				timestamp = EXCLUDED.timestamp,
+				window_end = EXCLUDED.window_end,
+				average_price = EXCLUDED.average_price,
+				min_price = EXCLUDED.min_price,
+				max_price = EXCLUDED.max_price,
+				tick_count = EXCLUDED.tick_count`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, item := range data {
+		_, err := stmt.ExecContext(ctx, item.PairName, item.Exchange, item.Timestamp,
+			item.WindowStart, item.WindowEnd, item.Interval, item.AveragePrice, item.MinPrice, item.MaxPrice, item.TickCount)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// BenchmarkSaveAggregatedData compares the COPY and staging table upsert with
+// the per-row INSERT loop it replaced, for a few batch sizes
+func BenchmarkSaveAggregatedData(b *testing.B) {
+	db := openBenchDB(b)
+	adapter := &Adapter{db: db}
+	ctx := context.Background()
+
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("copy/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := adapter.SaveAggregatedData(ctx, benchRows(size, i)); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*size), "ns/row")
+		})
+
+		b.Run(fmt.Sprintf("insert/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := insertRows(ctx, db, benchRows(size, i)); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*size), "ns/row")
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package postgresql
+
+import (
+	"context"
+
+	"marketflow/internal/domain/models"
+)
+
+// AmendAggregatedData folds a partial aggregate into the persisted row of the same window, creating the row if missing
+func (a *Adapter) AmendAggregatedData(ctx context.Context, data models.AggregatedData) error {
+	// Rows written before tick_count existed count as a single update
+	_, err := a.db.ExecContext(ctx, `INSERT INTO market_data (pair_name, exchange, timestamp, window_start, window_end, interval, average_price, min_price, max_price, tick_count)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			  ON CONFLICT (pair_name, exchange, window_start, interval) DO UPDATE SET
+				average_price = (market_data.average_price * GREATEST(market_data.tick_count, 1) + EXCLUDED.average_price * EXCLUDED.tick_count)
+					/ (GREATEST(market_data.tick_count, 1) + EXCLUDED.tick_count),
+				min_price = LEAST(market_data.min_price, EXCLUDED.min_price),
+				max_price = GREATEST(market_data.max_price, EXCLUDED.max_price),
+				tick_count = GREATEST(market_data.tick_count, 1) + EXCLUDED.tick_count`,
+		data.PairName, data.Exchange, data.Timestamp, data.WindowStart, data.WindowEnd, data.Interval,
+		data.AveragePrice, data.MinPrice, data.MaxPrice, data.TickCount)
+	return err
+}
+
+// SaveCorrections records updates that arrived after their window was aggregated
+func (a *Adapter) SaveCorrections(ctx context.Context, corrections []models.Correction) error {
+	if len(corrections) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO market_data_corrections (pair_name, exchange, window_start, window_end, price, event_time, received_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range corrections {
+		_, err := stmt.ExecContext(ctx, c.PairName, c.Exchange, c.WindowStart, c.WindowEnd, c.Price, c.EventTime, c.ReceivedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,15 @@
+-- Aggregated prices per symbol and exchange
+CREATE TABLE IF NOT EXISTS market_data (
+    id SERIAL PRIMARY KEY,
+    pair_name VARCHAR(20) NOT NULL,
+    exchange VARCHAR(50) NOT NULL,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    average_price DECIMAL(20, 8) NOT NULL,
+    min_price DECIMAL(20, 8) NOT NULL,
+    max_price DECIMAL(20, 8) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_data_pair_exchange ON market_data(pair_name, exchange);
+CREATE INDEX IF NOT EXISTS idx_market_data_timestamp ON market_data(timestamp);
+CREATE INDEX IF NOT EXISTS idx_market_data_created_at ON market_data(created_at);
@@ -0,0 +1,20 @@
+-- OHLC candles per symbol, exchange and interval
+CREATE TABLE IF NOT EXISTS candles (
+    id BIGSERIAL PRIMARY KEY,
+    pair_name VARCHAR(20) NOT NULL,
+    exchange VARCHAR(50) NOT NULL,
+    interval VARCHAR(10) NOT NULL,
+    open_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    close_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    open_price DECIMAL(20, 8) NOT NULL,
+    high_price DECIMAL(20, 8) NOT NULL,
+    low_price DECIMAL(20, 8) NOT NULL,
+    close_price DECIMAL(20, 8) NOT NULL,
+    tick_count BIGINT NOT NULL,
+    first_tick_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_tick_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (pair_name, exchange, interval, open_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_candles_pair_interval_open ON candles(pair_name, interval, open_time);
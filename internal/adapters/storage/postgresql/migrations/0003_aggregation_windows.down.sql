@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_market_data_window_start;
+ALTER TABLE market_data DROP COLUMN IF EXISTS window_end;
+ALTER TABLE market_data DROP COLUMN IF EXISTS window_start;
@@ -0,0 +1,13 @@
+-- Label rows with the wall-clock aligned window they aggregate. Rows written
+-- before windows existed were stamped when their one-minute window was aggregated.
+ALTER TABLE market_data ADD COLUMN IF NOT EXISTS window_start TIMESTAMP WITH TIME ZONE;
+ALTER TABLE market_data ADD COLUMN IF NOT EXISTS window_end TIMESTAMP WITH TIME ZONE;
+
+UPDATE market_data
+SET window_start = timestamp - INTERVAL '1 minute', window_end = timestamp
+WHERE window_start IS NULL OR window_end IS NULL;
+
+ALTER TABLE market_data ALTER COLUMN window_start SET NOT NULL;
+ALTER TABLE market_data ALTER COLUMN window_end SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_market_data_window_start ON market_data(pair_name, window_start);
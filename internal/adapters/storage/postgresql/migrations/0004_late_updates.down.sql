@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS market_data_corrections;
+ALTER TABLE market_data DROP COLUMN IF EXISTS tick_count;
@@ -0,0 +1,17 @@
+-- Count the updates in each window so late updates can be folded into it
+ALTER TABLE market_data ADD COLUMN IF NOT EXISTS tick_count BIGINT NOT NULL DEFAULT 0;
+
+-- Updates that arrived after their window was aggregated
+CREATE TABLE IF NOT EXISTS market_data_corrections (
+    id BIGSERIAL PRIMARY KEY,
+    pair_name VARCHAR(20) NOT NULL,
+    exchange VARCHAR(50) NOT NULL,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    window_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    price DECIMAL(20, 8) NOT NULL,
+    event_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_data_corrections_window ON market_data_corrections(pair_name, exchange, window_start);
@@ -0,0 +1,2 @@
+-- Dropping the column drops the unique key built on it
+ALTER TABLE market_data DROP COLUMN IF EXISTS interval;
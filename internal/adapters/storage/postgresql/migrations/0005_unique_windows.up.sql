@@ -0,0 +1,29 @@
+-- Make each window unique per symbol, exchange, start and interval so writes can upsert
+ALTER TABLE market_data ADD COLUMN IF NOT EXISTS interval VARCHAR(10);
+
+-- Name the width of existing windows in whole hours, minutes or seconds
+UPDATE market_data
+SET interval = CASE
+        WHEN seconds % 3600 = 0 THEN (seconds / 3600) || 'h'
+        WHEN seconds % 60 = 0 THEN (seconds / 60) || 'm'
+        ELSE seconds || 's'
+    END
+FROM (
+    SELECT id AS window_id, EXTRACT(EPOCH FROM window_end - window_start)::BIGINT AS seconds
+    FROM market_data
+) widths
+WHERE market_data.id = widths.window_id AND market_data.interval IS NULL;
+
+ALTER TABLE market_data ALTER COLUMN interval SET NOT NULL;
+
+-- Keep the most recently written row of every duplicated window
+DELETE FROM market_data older
+USING market_data newer
+WHERE older.pair_name = newer.pair_name
+  AND older.exchange = newer.exchange
+  AND older.window_start = newer.window_start
+  AND older.interval = newer.interval
+  AND older.id < newer.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS market_data_pair_name_exchange_window_start_interval_key
+    ON market_data(pair_name, exchange, window_start, interval);
@@ -4,26 +4,64 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"time"
 
 	_ "github.com/lib/pq"
 
+	"marketflow/internal/adapters/monitor"
 	"marketflow/internal/application/ports"
 	"marketflow/internal/config"
 	"marketflow/internal/domain/models"
 )
 
+const aggregatedColumns = `id, pair_name, exchange, timestamp, window_start, window_end, interval, average_price, min_price, max_price, tick_count`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAggregated(row rowScanner, item *models.AggregatedData) error {
+	return row.Scan(&item.ID, &item.PairName, &item.Exchange, &item.Timestamp,
+		&item.WindowStart, &item.WindowEnd, &item.Interval, &item.AveragePrice, &item.MinPrice, &item.MaxPrice, &item.TickCount)
+}
+
 // Adapter implements the StoragePort interface for PostgreSQL
 type Adapter struct {
-	db *sql.DB
+	db       *sql.DB
+	monitor  *monitor.Monitor
+	migrate  bool
+	migrated bool // only touched by the monitor's checks
 }
 
-// New creates a new PostgreSQL adapter
-func New(cfg config.DatabaseConfig) (ports.StoragePort, error) {
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Database, cfg.SSLMode)
+// migrateTimeout bounds how long pending migrations may take, including
+// waiting for another instance that holds the migration lock
+const migrateTimeout = 2 * time.Minute
+
+// New creates a new PostgreSQL adapter. It does not fail when the database is
+// unreachable: the connection is retried in the background, pending migrations
+// are applied once it succeeds when auto_migrate is set, and until then
+// queries return errors.
+func New(cfg config.PostgresConfig, logger *slog.Logger) (ports.StoragePort, error) {
+	db, err := sql.Open("postgres", dsn(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	a := &Adapter{
+		db:      db,
+		migrate: cfg.AutoMigrate,
+	}
+	a.monitor = monitor.New("postgres", a.check, logger)
+	a.monitor.Start()
+
+	return a, nil
+}
 
-	db, err := sql.Open("postgres", dsn)
+// Open connects to PostgreSQL and checks the connection
+func Open(cfg config.PostgresConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -33,22 +71,69 @@ func New(cfg config.DatabaseConfig) (ports.StoragePort, error) {
 	defer cancel()
 
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &Adapter{
-		db: db,
-	}, nil
+	return db, nil
+}
+
+func dsn(cfg config.PostgresConfig) string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s connect_timeout=5",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Database, cfg.SSLMode)
+}
+
+// check pings the database and, the first time it answers, applies pending migrations
+func (a *Adapter) check(ctx context.Context) error {
+	if err := a.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	if !a.migrate || a.migrated {
+		return nil
+	}
+
+	migrator, err := NewMigrator(a.db)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), migrateTimeout)
+	defer cancel()
+
+	if _, err := migrator.Up(ctx); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	a.migrated = true
+	return nil
+}
+
+// Health returns the state of the connection to the database
+func (a *Adapter) Health() models.DependencyHealth {
+	return a.monitor.Health()
+}
+
+// marketDataStaging receives aggregated rows before they are upserted into market_data
+var marketDataStaging = stagingTable{
+	name: "market_data_staging",
+	columns: []string{
+		"pair_name VARCHAR(20)", "exchange VARCHAR(50)", "timestamp TIMESTAMP WITH TIME ZONE",
+		"window_start TIMESTAMP WITH TIME ZONE", "window_end TIMESTAMP WITH TIME ZONE", "interval VARCHAR(10)",
+		"average_price DECIMAL(20, 8)", "min_price DECIMAL(20, 8)", "max_price DECIMAL(20, 8)", "tick_count BIGINT",
+	},
 }
 
-// SaveAggregatedData saves aggregated market data
+// SaveAggregatedData saves aggregated market data; saving a window again replaces it.
+// Rows are copied into a staging table and upserted with one statement.
 func (a *Adapter) SaveAggregatedData(ctx context.Context, data []models.AggregatedData) error {
 	if len(data) == 0 {
 		return nil
 	}
 
-	query := `INSERT INTO market_data (pair_name, exchange, timestamp, average_price, min_price, max_price)
-			  VALUES ($1, $2, $3, $4, $5, $6)`
+	rows := make([][]interface{}, 0, len(data))
+	for _, item := range data {
+		rows = append(rows, []interface{}{item.PairName, item.Exchange, item.Timestamp,
+			item.WindowStart, item.WindowEnd, item.Interval, item.AveragePrice, item.MinPrice, item.MaxPrice, item.TickCount})
+	}
 
 	tx, err := a.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -56,54 +141,71 @@ func (a *Adapter) SaveAggregatedData(ctx context.Context, data []models.Aggregat
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.PrepareContext(ctx, query)
-	if err != nil {
+	if err := copyIn(ctx, tx, marketDataStaging, rows); err != nil {
 		return err
 	}
-	defer stmt.Close()
 
-	for _, item := range data {
-		_, err := stmt.ExecContext(ctx, item.PairName, item.Exchange, item.Timestamp,
-			item.AveragePrice, item.MinPrice, item.MaxPrice)
-		if err != nil {
-			return err
-		}
+	// The last copy of a window in the batch wins
+	_, err = tx.ExecContext(ctx, `INSERT INTO market_data (pair_name, exchange, timestamp, window_start, window_end, interval, average_price, min_price, max_price, tick_count)
+			  SELECT DISTINCT ON (pair_name, exchange, window_start, interval)
+				pair_name, exchange, timestamp, window_start, window_end, interval, average_price, min_price, max_price, tick_count
+			  FROM market_data_staging
+			  ORDER BY pair_name, exchange, window_start, interval, seq DESC
+			  ON CONFLICT (pair_name, exchange, window_start, interval) DO UPDATE SET
+				timestamp = EXCLUDED.timestamp,
+				window_end = EXCLUDED.window_end,
+				average_price = EXCLUDED.average_price,
+				min_price = EXCLUDED.min_price,
+				max_price = EXCLUDED.max_price,
+				tick_count = EXCLUDED.tick_count`)
+	if err != nil {
+		return err
 	}
 
 	return tx.Commit()
 }
 
-// GetAggregatedData retrieves aggregated data within a time range
-func (a *Adapter) GetAggregatedData(ctx context.Context, symbol, exchange string, from, to time.Time) ([]models.AggregatedData, error) {
-	var query string
-	var args []interface{}
+// GetLastWindowEnd returns the end of the latest persisted window of a symbol on an exchange, or the zero time if none
+func (a *Adapter) GetLastWindowEnd(ctx context.Context, symbol, exchange string) (time.Time, error) {
+	var end sql.NullTime
+	err := a.db.QueryRowContext(ctx, `SELECT MAX(window_end) FROM market_data WHERE pair_name = $1 AND exchange = $2`,
+		symbol, exchange).Scan(&end)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return end.Time, nil
+}
+
+// GetAggregatedData retrieves up to limit windows starting within [from, to),
+// oldest first, resuming after the given row when after is set
+func (a *Adapter) GetAggregatedData(ctx context.Context, symbol, exchange string, from, to time.Time, after *models.AggregatedCursor, limit int) ([]models.AggregatedData, error) {
+	query := `SELECT ` + aggregatedColumns + `
+			  FROM market_data
+			  WHERE pair_name = $1 AND window_start >= $2 AND window_start < $3`
+	args := []interface{}{symbol, from, to}
 
 	if exchange != "" {
-		query = `SELECT id, pair_name, exchange, timestamp, average_price, min_price, max_price
-				 FROM market_data
-				 WHERE pair_name = $1 AND exchange = $2 AND timestamp BETWEEN $3 AND $4
-				 ORDER BY timestamp DESC`
-		args = []interface{}{symbol, exchange, from, to}
-	} else {
-		query = `SELECT id, pair_name, exchange, timestamp, average_price, min_price, max_price
-				 FROM market_data
-				 WHERE pair_name = $1 AND timestamp BETWEEN $2 AND $3
-				 ORDER BY timestamp DESC`
-		args = []interface{}{symbol, from, to}
+		args = append(args, exchange)
+		query += fmt.Sprintf(` AND exchange = $%d`, len(args))
+	}
+	if after != nil {
+		args = append(args, after.WindowStart, after.ID)
+		query += fmt.Sprintf(` AND (window_start, id) > ($%d, $%d)`, len(args)-1, len(args))
 	}
 
+	args = append(args, limit)
+	query += fmt.Sprintf(` ORDER BY window_start, id LIMIT $%d`, len(args))
+
 	rows, err := a.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var data []models.AggregatedData
+	data := []models.AggregatedData{}
 	for rows.Next() {
 		var item models.AggregatedData
-		err := rows.Scan(&item.ID, &item.PairName, &item.Exchange, &item.Timestamp,
-			&item.AveragePrice, &item.MinPrice, &item.MaxPrice)
-		if err != nil {
+		if err := scanAggregated(rows, &item); err != nil {
 			return nil, err
 		}
 		data = append(data, item)
@@ -120,14 +222,14 @@ func (a *Adapter) GetHighestPrice(ctx context.Context, symbol, exchange string,
 	var args []interface{}
 
 	if exchange != "" {
-		query = `SELECT id, pair_name, exchange, timestamp, average_price, min_price, max_price
+		query = `SELECT ` + aggregatedColumns + `
 				 FROM market_data
 				 WHERE pair_name = $1 AND exchange = $2 AND timestamp >= $3
 				 ORDER BY max_price DESC
 				 LIMIT 1`
 		args = []interface{}{symbol, exchange, from}
 	} else {
-		query = `SELECT id, pair_name, exchange, timestamp, average_price, min_price, max_price
+		query = `SELECT ` + aggregatedColumns + `
 				 FROM market_data
 				 WHERE pair_name = $1 AND timestamp >= $2
 				 ORDER BY max_price DESC
@@ -136,9 +238,7 @@ func (a *Adapter) GetHighestPrice(ctx context.Context, symbol, exchange string,
 	}
 
 	var item models.AggregatedData
-	err := a.db.QueryRowContext(ctx, query, args...).Scan(
-		&item.ID, &item.PairName, &item.Exchange, &item.Timestamp,
-		&item.AveragePrice, &item.MinPrice, &item.MaxPrice)
+	err := scanAggregated(a.db.QueryRowContext(ctx, query, args...), &item)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -158,14 +258,14 @@ func (a *Adapter) GetLowestPrice(ctx context.Context, symbol, exchange string, p
 	var args []interface{}
 
 	if exchange != "" {
-		query = `SELECT id, pair_name, exchange, timestamp, average_price, min_price, max_price
+		query = `SELECT ` + aggregatedColumns + `
 				 FROM market_data
 				 WHERE pair_name = $1 AND exchange = $2 AND timestamp >= $3
 				 ORDER BY min_price ASC
 				 LIMIT 1`
 		args = []interface{}{symbol, exchange, from}
 	} else {
-		query = `SELECT id, pair_name, exchange, timestamp, average_price, min_price, max_price
+		query = `SELECT ` + aggregatedColumns + `
 				 FROM market_data
 				 WHERE pair_name = $1 AND timestamp >= $2
 				 ORDER BY min_price ASC
@@ -174,9 +274,7 @@ func (a *Adapter) GetLowestPrice(ctx context.Context, symbol, exchange string, p
 	}
 
 	var item models.AggregatedData
-	err := a.db.QueryRowContext(ctx, query, args...).Scan(
-		&item.ID, &item.PairName, &item.Exchange, &item.Timestamp,
-		&item.AveragePrice, &item.MinPrice, &item.MaxPrice)
+	err := scanAggregated(a.db.QueryRowContext(ctx, query, args...), &item)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -200,6 +298,8 @@ func (a *Adapter) GetAveragePrice(ctx context.Context, symbol, exchange string,
 					$1 as pair_name,
 					$2 as exchange,
 					NOW() as timestamp,
+					MIN(window_start) as window_start,
+					MAX(window_end) as window_end,
 					AVG(average_price) as average_price,
 					MIN(min_price) as min_price,
 					MAX(max_price) as max_price,
@@ -213,6 +313,8 @@ func (a *Adapter) GetAveragePrice(ctx context.Context, symbol, exchange string,
 					$1 as pair_name,
 					'aggregated' as exchange,
 					NOW() as timestamp,
+					MIN(window_start) as window_start,
+					MAX(window_end) as window_end,
 					AVG(average_price) as average_price,
 					MIN(min_price) as min_price,
 					MAX(max_price) as max_price,
@@ -226,7 +328,7 @@ func (a *Adapter) GetAveragePrice(ctx context.Context, symbol, exchange string,
 	var item models.AggregatedData
 	var recordCount int
 	err := a.db.QueryRowContext(ctx, query, args...).Scan(
-		&item.PairName, &item.Exchange, &item.Timestamp,
+		&item.PairName, &item.Exchange, &item.Timestamp, &item.WindowStart, &item.WindowEnd,
 		&item.AveragePrice, &item.MinPrice, &item.MaxPrice, &recordCount)
 
 	if err != nil {
@@ -245,5 +347,6 @@ func (a *Adapter) GetAveragePrice(ctx context.Context, symbol, exchange string,
 
 // Close closes the storage connection
 func (a *Adapter) Close() error {
+	a.monitor.Stop()
 	return a.db.Close()
 }
@@ -0,0 +1,713 @@
+package spool
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"marketflow/internal/application/ports"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+)
+
+const (
+	activeFile     = "current.jsonl"
+	quarantineFile = "quarantine.jsonl"
+	segmentPrefix  = "segment-"
+	segmentSuffix  = ".jsonl"
+	offsetSuffix   = ".offset" // next to a segment, the position of its first batch not yet replayed
+
+	// maxRecordSize bounds one spooled batch when reading it back
+	maxRecordSize = 64 << 20
+
+	kindMarketData   = "market_data"
+	kindCandles      = "candles"
+	kindAmend        = "amend"
+	kindCorrections  = "corrections"
+	kindAmendCandles = "amend_candles"
+)
+
+// ErrSpoolFull is returned when a batch cannot be spooled without exceeding max_size_mb
+var ErrSpoolFull = errors.New("spool is full")
+
+// record is one spooled batch, stored as a line of JSON
+type record struct {
+	Kind        string          `json:"kind"`
+	SpooledAt   time.Time       `json:"spooled_at"`
+	Rows        []row           `json:"rows,omitempty"` // market_data rows, or the partial aggregates to amend
+	Candles     []models.Candle `json:"candles,omitempty"`
+	Corrections []correction    `json:"corrections,omitempty"`
+}
+
+// size returns the number of rows in the batch
+func (r record) size() int {
+	return len(r.Rows) + len(r.Candles) + len(r.Corrections)
+}
+
+// row mirrors models.AggregatedData with stable field names for the spool files
+type row struct {
+	PairName     string    `json:"pair_name"`
+	Exchange     string    `json:"exchange"`
+	Timestamp    time.Time `json:"timestamp"`
+	WindowStart  time.Time `json:"window_start"`
+	WindowEnd    time.Time `json:"window_end"`
+	Interval     string    `json:"interval"`
+	AveragePrice float64   `json:"average_price"`
+	MinPrice     float64   `json:"min_price"`
+	MaxPrice     float64   `json:"max_price"`
+	TickCount    int64     `json:"tick_count"`
+}
+
+func toRows(data []models.AggregatedData) []row {
+	rows := make([]row, 0, len(data))
+	for _, item := range data {
+		rows = append(rows, row{
+			PairName: item.PairName, Exchange: item.Exchange, Timestamp: item.Timestamp,
+			WindowStart: item.WindowStart, WindowEnd: item.WindowEnd, Interval: item.Interval,
+			AveragePrice: item.AveragePrice, MinPrice: item.MinPrice, MaxPrice: item.MaxPrice, TickCount: item.TickCount,
+		})
+	}
+	return rows
+}
+
+func fromRows(rows []row) []models.AggregatedData {
+	data := make([]models.AggregatedData, 0, len(rows))
+	for _, r := range rows {
+		data = append(data, models.AggregatedData{
+			PairName: r.PairName, Exchange: r.Exchange, Timestamp: r.Timestamp,
+			WindowStart: r.WindowStart, WindowEnd: r.WindowEnd, Interval: r.Interval,
+			AveragePrice: r.AveragePrice, MinPrice: r.MinPrice, MaxPrice: r.MaxPrice, TickCount: r.TickCount,
+		})
+	}
+	return data
+}
+
+// correction mirrors models.Correction with stable field names for the spool files
+type correction struct {
+	PairName    string    `json:"pair_name"`
+	Exchange    string    `json:"exchange"`
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	Price       float64   `json:"price"`
+	EventTime   time.Time `json:"event_time"`
+	ReceivedAt  time.Time `json:"received_at"`
+}
+
+// quarantined is a spooled batch the database rejected, kept for inspection
+type quarantined struct {
+	QuarantinedAt time.Time `json:"quarantined_at"`
+	Error         string    `json:"error"`
+	Batch         string    `json:"batch"` // the spooled line, which may not be valid JSON
+}
+
+// segment describes the batches of a spool file that are still to be replayed
+type segment struct {
+	path    string
+	offset  int64 // position of the first batch not yet replayed
+	size    int64
+	batches int
+	rows    int
+	oldest  time.Time
+}
+
+func (s *segment) add(size int64, rows int, at time.Time) {
+	s.size += size
+	s.batches++
+	s.rows += rows
+	if !at.IsZero() && (s.oldest.IsZero() || at.Before(s.oldest)) {
+		s.oldest = at
+	}
+}
+
+// Storage wraps a StoragePort and appends the batches it cannot write because
+// the database is unavailable to files in a directory, replaying them in order
+// once it is back. While anything is spooled, new batches are spooled behind it
+// so they are written in order. Batches the database rejects for another
+// reason are not spooled, and spooled ones it rejects on replay are moved to a
+// quarantine file. The replay position of each file is saved after every
+// batch, so a batch is only written again if the process stops in between.
+type Storage struct {
+	ports.StoragePort
+
+	dir         string
+	maxSize     int64
+	interval    time.Duration
+	unavailable func(error) bool
+	logger      *slog.Logger
+
+	mu          sync.Mutex
+	active      *os.File
+	current     segment   // the file new batches are appended to
+	segments    []segment // rotated files waiting to be replayed, oldest first
+	dropped     uint64
+	replayed    uint64
+	quarantined uint64
+	lastReplay  time.Time
+	lastErr     string
+
+	replayMu sync.Mutex
+	wake     chan struct{}
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// New wraps storage with a spool in cfg.Dir and starts replaying anything
+// left there by a previous run. unavailable tells the write errors of an
+// unreachable database, which are spooled, from the others.
+func New(storage ports.StoragePort, cfg config.SpoolConfig, unavailable func(error) bool, logger *slog.Logger) (*Storage, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	s := &Storage{
+		StoragePort: storage,
+		dir:         cfg.Dir,
+		maxSize:     int64(cfg.MaxSizeMB) << 20,
+		interval:    cfg.ReplayInterval.Std(),
+		unavailable: unavailable,
+		logger:      logger.With("component", "spool"),
+		current:     segment{path: filepath.Join(cfg.Dir, activeFile)},
+		wake:        make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if s.pending() {
+		s.logger.Warn("Found spooled batches from a previous run", "batches", s.current.batches+s.batchesInSegments())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go s.replayLoop(ctx)
+
+	return s, nil
+}
+
+// SaveAggregatedData saves aggregated rows, spooling them if the database is unavailable
+func (s *Storage) SaveAggregatedData(ctx context.Context, data []models.AggregatedData) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return s.save(record{Kind: kindMarketData, Rows: toRows(data)}, func() error {
+		return s.StoragePort.SaveAggregatedData(ctx, data)
+	})
+}
+
+// AmendAggregatedData folds a partial aggregate into its persisted window,
+// spooling it if the database is unavailable
+func (s *Storage) AmendAggregatedData(ctx context.Context, data models.AggregatedData) error {
+	return s.save(record{Kind: kindAmend, Rows: toRows([]models.AggregatedData{data})}, func() error {
+		return s.StoragePort.AmendAggregatedData(ctx, data)
+	})
+}
+
+// SaveCorrections records late updates, spooling them if the database is unavailable
+func (s *Storage) SaveCorrections(ctx context.Context, corrections []models.Correction) error {
+	if len(corrections) == 0 {
+		return nil
+	}
+
+	rows := make([]correction, 0, len(corrections))
+	for _, c := range corrections {
+		rows = append(rows, correction(c))
+	}
+
+	return s.save(record{Kind: kindCorrections, Corrections: rows}, func() error {
+		return s.StoragePort.SaveCorrections(ctx, corrections)
+	})
+}
+
+// SaveCandles saves candles, spooling them if the database is unavailable
+func (s *Storage) SaveCandles(ctx context.Context, candles []models.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	return s.save(record{Kind: kindCandles, Candles: candles}, func() error {
+		return s.StoragePort.SaveCandles(ctx, candles)
+	})
+}
+
+// AmendCandles folds partial candles into their persisted candles, spooling
+// them if the database is unavailable
+func (s *Storage) AmendCandles(ctx context.Context, candles []models.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	return s.save(record{Kind: kindAmendCandles, Candles: candles}, func() error {
+		return s.StoragePort.AmendCandles(ctx, candles)
+	})
+}
+
+// GetSpoolStatus returns the size and age of the batches waiting to be replayed
+func (s *Storage) GetSpoolStatus() models.SpoolStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := models.SpoolStatus{
+		Enabled:     true,
+		Dir:         s.dir,
+		SizeBytes:   s.current.size,
+		Batches:     s.current.batches,
+		Rows:        s.current.rows,
+		Dropped:     s.dropped,
+		Replayed:    s.replayed,
+		Quarantined: s.quarantined,
+		LastError:   s.lastErr,
+	}
+
+	oldest := s.current.oldest
+	for _, seg := range s.segments {
+		status.SizeBytes += seg.size
+		status.Batches += seg.batches
+		status.Rows += seg.rows
+		if !seg.oldest.IsZero() && (oldest.IsZero() || seg.oldest.Before(oldest)) {
+			oldest = seg.oldest
+		}
+	}
+	if !oldest.IsZero() {
+		status.OldestAt = &oldest
+		status.AgeSeconds = time.Since(oldest).Seconds()
+	}
+	if !s.lastReplay.IsZero() {
+		lastReplay := s.lastReplay
+		status.LastReplayAt = &lastReplay
+	}
+	return status
+}
+
+// Close stops replaying, closes the spool file and the wrapped storage
+func (s *Storage) Close() error {
+	s.cancel()
+	<-s.done
+
+	s.mu.Lock()
+	if s.active != nil {
+		s.active.Close()
+		s.active = nil
+	}
+	s.mu.Unlock()
+
+	return s.StoragePort.Close()
+}
+
+// save writes a batch through unless earlier batches are spooled, and spools
+// it if the write fails because the database is unavailable
+func (s *Storage) save(rec record, write func() error) error {
+	if !s.pending() {
+		err := write()
+		if err == nil || !s.isUnavailable(err) {
+			return err
+		}
+		s.logger.Warn("Database unavailable, spooling batch", "error", err, "kind", rec.Kind)
+	}
+
+	if err := s.append(rec); err != nil {
+		s.logger.Error("Failed to spool batch, it is lost", "error", err, "kind", rec.Kind)
+		return err
+	}
+
+	s.signal()
+	return nil
+}
+
+// isUnavailable reports whether a write failed because the database could not
+// be reached; any error while its connection is known to be down counts
+func (s *Storage) isUnavailable(err error) bool {
+	return s.unavailable(err) || s.StoragePort.Health().State == models.DependencyDown
+}
+
+func (s *Storage) append(rec record) error {
+	rec.SpooledAt = time.Now()
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sizeLocked()+int64(len(line)) > s.maxSize {
+		s.dropped++
+		return ErrSpoolFull
+	}
+
+	if s.active == nil {
+		f, err := os.OpenFile(s.current.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		s.active = f
+	}
+
+	if _, err := s.active.Write(line); err != nil {
+		return err
+	}
+	if err := s.active.Sync(); err != nil {
+		return err
+	}
+
+	s.current.add(int64(len(line)), rec.size(), rec.SpooledAt)
+	return nil
+}
+
+func (s *Storage) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Storage) replayLoop(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		// Wait for the database to come back rather than failing every attempt
+		if s.pending() && s.StoragePort.Health().State == models.DependencyUp {
+			s.replay(ctx)
+		}
+	}
+}
+
+// replay writes spooled batches oldest first, stopping when the database is unavailable
+func (s *Storage) replay(ctx context.Context) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	if err := s.rotate(); err != nil {
+		s.logger.Error("Failed to rotate spool file", "error", err)
+		return
+	}
+
+	for {
+		s.mu.Lock()
+		if len(s.segments) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		seg := s.segments[0]
+		s.mu.Unlock()
+
+		err := s.replaySegment(ctx, seg)
+
+		s.mu.Lock()
+		s.lastReplay = time.Now()
+		if err != nil {
+			s.lastErr = err.Error()
+			s.mu.Unlock()
+			s.logger.Warn("Spool replay failed, will retry", "error", err, "file", seg.path)
+			return
+		}
+		s.lastErr = ""
+		s.segments = s.segments[1:]
+		s.mu.Unlock()
+
+		if err := os.Remove(seg.path); err != nil {
+			s.logger.Error("Failed to remove replayed spool file", "error", err, "file", seg.path)
+		}
+		if err := os.Remove(seg.path + offsetSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.Error("Failed to remove spool offset file", "error", err, "file", seg.path+offsetSuffix)
+		}
+		s.logger.Info("Replayed spooled batches", "file", filepath.Base(seg.path), "batches", seg.batches, "rows", seg.rows)
+	}
+}
+
+// replaySegment writes the batches of a spool file from its saved offset. A
+// batch the database rejects is quarantined and replay moves on; one that
+// fails because the database is unavailable stops the replay, to be retried
+// from that batch.
+func (s *Storage) replaySegment(ctx context.Context, seg segment) error {
+	f, err := os.Open(seg.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(seg.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	offset := seg.offset
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxRecordSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		size := int64(len(line) + 1)
+
+		var rec record
+		err := json.Unmarshal(line, &rec)
+		if err == nil {
+			s.updateSegment(seg.path, func(seg *segment) { seg.oldest = rec.SpooledAt })
+			err = s.apply(ctx, rec)
+			if err != nil && s.isUnavailable(err) {
+				return err
+			}
+		}
+
+		// Unreadable batches, cut short by a crash while they were spooled,
+		// and the ones the database rejects could never be replayed
+		if err != nil {
+			s.logger.Warn("Quarantining spooled batch", "error", err, "kind", rec.Kind, "file", seg.path)
+			if err := s.quarantine(line, err); err != nil {
+				return fmt.Errorf("failed to quarantine batch: %w", err)
+			}
+		}
+
+		offset += size
+		if err := writeOffset(seg.path, offset); err != nil {
+			return fmt.Errorf("failed to save replay offset: %w", err)
+		}
+
+		rejected := err != nil
+		s.updateSegment(seg.path, func(seg *segment) {
+			seg.offset = offset
+			seg.size -= size
+			seg.batches--
+			seg.rows -= rec.size()
+			if rejected {
+				s.quarantined++
+			} else {
+				s.replayed++
+			}
+		})
+	}
+
+	return scanner.Err()
+}
+
+// apply writes a spooled batch to the wrapped storage
+func (s *Storage) apply(ctx context.Context, rec record) error {
+	switch rec.Kind {
+	case kindMarketData:
+		return s.StoragePort.SaveAggregatedData(ctx, fromRows(rec.Rows))
+	case kindCandles:
+		return s.StoragePort.SaveCandles(ctx, rec.Candles)
+	case kindAmend:
+		for _, data := range fromRows(rec.Rows) {
+			if err := s.StoragePort.AmendAggregatedData(ctx, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	case kindCorrections:
+		corrections := make([]models.Correction, 0, len(rec.Corrections))
+		for _, c := range rec.Corrections {
+			corrections = append(corrections, models.Correction(c))
+		}
+		return s.StoragePort.SaveCorrections(ctx, corrections)
+	case kindAmendCandles:
+		return s.StoragePort.AmendCandles(ctx, rec.Candles)
+	default:
+		return fmt.Errorf("unknown batch kind %q", rec.Kind)
+	}
+}
+
+// quarantine appends a batch that cannot be replayed to the quarantine file
+func (s *Storage) quarantine(line []byte, reason error) error {
+	entry, err := json.Marshal(quarantined{QuarantinedAt: time.Now(), Error: reason.Error(), Batch: string(line)})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(s.dir, quarantineFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(entry, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// updateSegment changes the waiting segment stored at path under s.mu
+func (s *Storage) updateSegment(path string, change func(seg *segment)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.segments {
+		if s.segments[i].path == path {
+			change(&s.segments[i])
+			return
+		}
+	}
+}
+
+// writeOffset saves the replay position of a segment, replacing the previous one atomically
+func writeOffset(path string, offset int64) error {
+	tmp := path + offsetSuffix + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(strconv.FormatInt(offset, 10)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path+offsetSuffix)
+}
+
+// readOffset returns the saved replay position of a segment, or 0 if none was saved
+func readOffset(path string) (int64, error) {
+	data, err := os.ReadFile(path + offsetSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// rotate moves the file new batches are appended to behind the files waiting to be replayed
+func (s *Storage) rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current.size == 0 {
+		return nil
+	}
+
+	if s.active != nil {
+		if err := s.active.Close(); err != nil {
+			return err
+		}
+		s.active = nil
+	}
+
+	seg := s.current
+	seg.path = filepath.Join(s.dir, fmt.Sprintf("%s%020d%s", segmentPrefix, time.Now().UnixNano(), segmentSuffix))
+	if err := os.Rename(s.current.path, seg.path); err != nil {
+		return err
+	}
+
+	s.segments = append(s.segments, seg)
+	s.current = segment{path: s.current.path}
+	return nil
+}
+
+// load picks up the spool files left by a previous run, from where their replay stopped
+func (s *Storage) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, segmentPrefix) && strings.HasSuffix(name, segmentSuffix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(s.dir, name)
+		offset, err := readOffset(path)
+		if err != nil {
+			return fmt.Errorf("failed to read replay offset of %s: %w", name, err)
+		}
+		seg, err := scan(path, offset)
+		if err != nil {
+			return err
+		}
+		s.segments = append(s.segments, seg)
+	}
+
+	if _, err := os.Stat(s.current.path); err == nil {
+		current, err := scan(s.current.path, 0)
+		if err != nil {
+			return err
+		}
+		s.current = current
+	}
+	return nil
+}
+
+// scan reads a spool file from offset to count the batches and rows left
+func scan(path string, offset int64) (segment, error) {
+	seg := segment{path: path, offset: offset}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return seg, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return seg, err
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxRecordSize)
+	for scanner.Scan() {
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			seg.add(int64(len(scanner.Bytes())+1), 0, time.Time{})
+			continue
+		}
+		seg.add(int64(len(scanner.Bytes())+1), rec.size(), rec.SpooledAt)
+	}
+	return seg, scanner.Err()
+}
+
+func (s *Storage) pending() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current.size > 0 || len(s.segments) > 0
+}
+
+// sizeLocked returns the total size of the spool; callers must hold s.mu
+func (s *Storage) sizeLocked() int64 {
+	size := s.current.size
+	for _, seg := range s.segments {
+		size += seg.size
+	}
+	return size
+}
+
+func (s *Storage) batchesInSegments() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batches := 0
+	for _, seg := range s.segments {
+		batches += seg.batches
+	}
+	return batches
+}
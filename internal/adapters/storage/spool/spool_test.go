@@ -0,0 +1,211 @@
+package spool
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"marketflow/internal/application/ports"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+)
+
+var (
+	errUnavailable = errors.New("connection refused")
+	errRejected    = errors.New("value too long for type character varying(20)")
+)
+
+// fakeStorage records the writes it accepts; fail decides the error of each one
+type fakeStorage struct {
+	ports.StoragePort
+
+	mu      sync.Mutex
+	state   models.DependencyState
+	fail    func(data models.AggregatedData) error
+	saved   []models.AggregatedData
+	amended []models.AggregatedData
+}
+
+func (f *fakeStorage) SaveAggregatedData(ctx context.Context, data []models.AggregatedData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, item := range data {
+		if f.fail != nil {
+			if err := f.fail(item); err != nil {
+				return err
+			}
+		}
+	}
+	f.saved = append(f.saved, data...)
+	return nil
+}
+
+func (f *fakeStorage) AmendAggregatedData(ctx context.Context, data models.AggregatedData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail != nil {
+		if err := f.fail(data); err != nil {
+			return err
+		}
+	}
+	f.amended = append(f.amended, data)
+	return nil
+}
+
+func (f *fakeStorage) Health() models.DependencyHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return models.DependencyHealth{State: f.state}
+}
+
+func (f *fakeStorage) Close() error { return nil }
+
+func (f *fakeStorage) set(state models.DependencyState, fail func(models.AggregatedData) error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state = state
+	f.fail = fail
+}
+
+func (f *fakeStorage) savedPairs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var pairs []string
+	for _, item := range f.saved {
+		pairs = append(pairs, item.PairName)
+	}
+	return pairs
+}
+
+func newTestSpool(t *testing.T, storage *fakeStorage, dir string) *Storage {
+	t.Helper()
+	cfg := config.SpoolConfig{Enabled: true, Dir: dir, MaxSizeMB: 1, ReplayInterval: config.Duration(time.Hour)}
+	s, err := New(storage, cfg, func(err error) bool { return errors.Is(err, errUnavailable) }, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func batch(pair string) []models.AggregatedData {
+	return []models.AggregatedData{{PairName: pair, Exchange: "exchange1", Interval: "1m", TickCount: 1}}
+}
+
+func failOn(pair string, err error) func(models.AggregatedData) error {
+	return func(data models.AggregatedData) error {
+		if data.PairName == pair {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestSpoolOnlySpoolsWhenTheDatabaseIsUnavailable(t *testing.T) {
+	storage := &fakeStorage{state: models.DependencyUp, fail: failOn("BAD", errRejected)}
+	s := newTestSpool(t, storage, t.TempDir())
+	defer s.Close()
+
+	if err := s.SaveAggregatedData(context.Background(), batch("BAD")); !errors.Is(err, errRejected) {
+		t.Fatalf("rejected batch: got %v, want %v", err, errRejected)
+	}
+	if status := s.GetSpoolStatus(); status.Batches != 0 {
+		t.Fatalf("rejected batch was spooled: %+v", status)
+	}
+
+	storage.set(models.DependencyUp, failOn("BTCUSDT", errUnavailable))
+	if err := s.SaveAggregatedData(context.Background(), batch("BTCUSDT")); err != nil {
+		t.Fatalf("unavailable database: got %v, want the batch spooled", err)
+	}
+	if status := s.GetSpoolStatus(); status.Batches != 1 {
+		t.Fatalf("batch was not spooled: %+v", status)
+	}
+}
+
+func TestSpoolQuarantinesRejectedBatchesAndResumesFromTheOffset(t *testing.T) {
+	dir := t.TempDir()
+	storage := &fakeStorage{state: models.DependencyDown, fail: func(models.AggregatedData) error { return errUnavailable }}
+	s := newTestSpool(t, storage, dir)
+
+	ctx := context.Background()
+	for _, pair := range []string{"A", "BAD", "B", "C"} {
+		if err := s.SaveAggregatedData(ctx, batch(pair)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.AmendAggregatedData(ctx, batch("D")[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// The database rejects BAD for good and goes away again at C
+	storage.set(models.DependencyUp, func(data models.AggregatedData) error {
+		switch data.PairName {
+		case "BAD":
+			return errRejected
+		case "C":
+			return errUnavailable
+		}
+		return nil
+	})
+	s.replay(ctx)
+
+	status := s.GetSpoolStatus()
+	if got := storage.savedPairs(); len(got) != 2 || got[0] != "A" || got[1] != "B" {
+		t.Fatalf("saved %v, want [A B]", got)
+	}
+	if status.Replayed != 2 || status.Quarantined != 1 || status.Batches != 2 || status.Rows != 2 {
+		t.Fatalf("unexpected status after the partial replay %+v", status)
+	}
+	if status.LastError == "" {
+		t.Fatal("the failed replay is not reported")
+	}
+	s.Close()
+
+	// A restart resumes at C instead of writing A and B again
+	storage.set(models.DependencyUp, nil)
+	s = newTestSpool(t, storage, dir)
+	defer s.Close()
+	if status := s.GetSpoolStatus(); status.Batches != 2 {
+		t.Fatalf("reloaded %d batches, want 2", status.Batches)
+	}
+	s.replay(ctx)
+
+	if got := storage.savedPairs(); len(got) != 3 || got[2] != "C" {
+		t.Fatalf("saved %v, want [A B C]", got)
+	}
+	if len(storage.amended) != 1 || storage.amended[0].PairName != "D" {
+		t.Fatalf("amended %+v, want D once", storage.amended)
+	}
+	if status := s.GetSpoolStatus(); status.Replayed != 2 || status.Batches != 0 || status.SizeBytes != 0 {
+		t.Fatalf("unexpected status after the replay %+v", status)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != quarantineFile {
+			t.Errorf("%s left in the spool directory", entry.Name())
+		}
+	}
+
+	f, err := os.Open(filepath.Join(dir, quarantineFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := 0
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		lines++
+	}
+	if lines != 1 {
+		t.Fatalf("quarantined %d batches, want 1", lines)
+	}
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"marketflow/internal/application/usecases"
+)
+
+// AdminHandler handles administrative requests
+type AdminHandler struct {
+	reloadUseCase *usecases.ReloadUseCase
+	logger        *slog.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(reloadUseCase *usecases.ReloadUseCase, logger *slog.Logger) *AdminHandler {
+	return &AdminHandler{
+		reloadUseCase: reloadUseCase,
+		logger:        logger,
+	}
+}
+
+// Reload handles POST /admin/reload
+func (h *AdminHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	result, err := h.reloadUseCase.Reload()
+	if err != nil {
+		writeUseCaseError(w, h.logger, err, "Failed to reload configuration")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
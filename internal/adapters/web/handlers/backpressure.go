@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"marketflow/internal/application/usecases"
+)
+
+// BackpressureHandler handles backpressure statistics requests
+type BackpressureHandler struct {
+	dataProcessingUseCase *usecases.DataProcessingUseCase
+	logger                *slog.Logger
+}
+
+// NewBackpressureHandler creates a new backpressure handler
+func NewBackpressureHandler(dataProcessingUseCase *usecases.DataProcessingUseCase, logger *slog.Logger) *BackpressureHandler {
+	return &BackpressureHandler{
+		dataProcessingUseCase: dataProcessingUseCase,
+		logger:                logger,
+	}
+}
+
+// Handle handles backpressure statistics requests
+func (h *BackpressureHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"sources": h.dataProcessingUseCase.GetOverflowStats(),
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
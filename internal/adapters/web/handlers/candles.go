@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"marketflow/internal/application/usecases"
+)
+
+// defaultCandleCount is how many candles are returned when no start time is given
+const defaultCandleCount = 100
+
+// CandlesHandler handles OHLC candle requests
+type CandlesHandler struct {
+	marketDataUseCase *usecases.MarketDataUseCase
+	logger            *slog.Logger
+}
+
+// NewCandlesHandler creates a new candles handler
+func NewCandlesHandler(marketDataUseCase *usecases.MarketDataUseCase, logger *slog.Logger) *CandlesHandler {
+	return &CandlesHandler{
+		marketDataUseCase: marketDataUseCase,
+		logger:            logger,
+	}
+}
+
+// Handle handles GET /candles/{symbol}?interval=&exchange=&from=&to=
+func (h *CandlesHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	symbol := r.PathValue("symbol")
+
+	query := r.URL.Query()
+	exchange := query.Get("exchange")
+
+	interval := query.Get("interval")
+	if interval == "" {
+		interval = h.defaultInterval()
+	}
+
+	to := time.Now()
+	if value := query.Get("to"); value != "" {
+		parsed, err := parseTime(value)
+		if err != nil {
+			WriteInvalidParameter(w, "to", value, err.Error())
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-time.Hour)
+	if width, err := time.ParseDuration(interval); err == nil && width > 0 {
+		from = to.Add(-defaultCandleCount * width)
+	}
+	if value := query.Get("from"); value != "" {
+		parsed, err := parseTime(value)
+		if err != nil {
+			WriteInvalidParameter(w, "from", value, err.Error())
+			return
+		}
+		from = parsed
+	}
+
+	candles, err := h.marketDataUseCase.GetCandles(r.Context(), symbol, exchange, interval, from, to)
+	if err != nil {
+		writeUseCaseError(w, h.logger, err, "Failed to get candles", "symbol", symbol, "interval", interval)
+		return
+	}
+
+	response := map[string]interface{}{
+		"symbol":   symbol,
+		"exchange": exchange,
+		"interval": interval,
+		"from":     from,
+		"to":       to,
+		"candles":  candles,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// defaultInterval prefers one-minute candles when they are built
+func (h *CandlesHandler) defaultInterval() string {
+	intervals := h.marketDataUseCase.GetCandleIntervals()
+	for _, interval := range intervals {
+		if interval == "1m" {
+			return interval
+		}
+	}
+	if len(intervals) > 0 {
+		return intervals[0]
+	}
+	return "1m"
+}
+
+// parseTime accepts RFC3339 timestamps or Unix milliseconds
+func parseTime(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 or Unix milliseconds, got %q", value)
+	}
+	return t, nil
+}
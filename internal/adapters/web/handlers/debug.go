@@ -28,7 +28,8 @@ func NewDebugHandler(marketDataUseCase *usecases.MarketDataUseCase, logger *slog
 // Handle handles debug requests
 func (h *DebugHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		w.Header().Set("Allow", "GET")
+		WriteError(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed", nil)
 		return
 	}
 
@@ -40,7 +41,7 @@ func (h *DebugHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	case "postgres":
 		h.handlePostgresDebug(w, r)
 	default:
-		http.Error(w, "Unknown debug endpoint", http.StatusBadRequest)
+		WriteError(w, http.StatusNotFound, CodeNotFound, "Unknown debug endpoint", nil)
 	}
 }
 
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"marketflow/internal/application/ports"
+	"marketflow/internal/application/usecases"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/symbols"
+)
+
+// Error codes of the error envelope; every code is listed in the Error schema
+// of the OpenAPI document
+const (
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeInternal         = "internal_error"
+	CodeInvalidParameter = "invalid_parameter"
+	CodeInvalidBody      = "invalid_body"
+	CodeInvalidConfig    = "invalid_config"
+	CodeHandshakeFailed  = "handshake_failed"
+	CodeNotImplemented   = "not_implemented"
+
+	CodeUnknownSymbol = "unknown_symbol"
+	CodeInvalidSymbol = "invalid_symbol"
+	CodeSymbolExists  = "symbol_exists"
+
+	CodeUnknownExchange  = "unknown_exchange"
+	CodeInvalidInterval  = "invalid_interval"
+	CodeInvalidRange     = "invalid_range"
+	CodeInvalidLimit     = "invalid_limit"
+	CodeInvalidCursor    = "invalid_cursor"
+	CodeExchangeNotFound = "exchange_not_found"
+	CodeExchangeExists   = "exchange_exists"
+	CodeInvalidExchange  = "invalid_exchange"
+)
+
+// ErrorBody is the body of every error response
+type ErrorBody struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// errorStatuses maps the errors of the use cases to a status and error code, most specific first
+var errorStatuses = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{usecases.ErrUnknownSymbol, http.StatusNotFound, CodeUnknownSymbol},
+	{symbols.ErrSymbolNotFound, http.StatusNotFound, CodeUnknownSymbol},
+	{usecases.ErrInvalidSymbol, http.StatusBadRequest, CodeInvalidSymbol},
+	{symbols.ErrInvalidSymbol, http.StatusBadRequest, CodeInvalidSymbol},
+	{symbols.ErrSymbolExists, http.StatusConflict, CodeSymbolExists},
+	{usecases.ErrUnknownExchange, http.StatusBadRequest, CodeUnknownExchange},
+	{usecases.ErrInvalidInterval, http.StatusBadRequest, CodeInvalidInterval},
+	{usecases.ErrInvalidRange, http.StatusBadRequest, CodeInvalidRange},
+	{usecases.ErrInvalidLimit, http.StatusBadRequest, CodeInvalidLimit},
+	{usecases.ErrInvalidCursor, http.StatusBadRequest, CodeInvalidCursor},
+	{ports.ErrExchangeNotFound, http.StatusNotFound, CodeExchangeNotFound},
+	{ports.ErrExchangeExists, http.StatusConflict, CodeExchangeExists},
+	{usecases.ErrInvalidExchange, http.StatusBadRequest, CodeInvalidExchange},
+	{usecases.ErrExchangeManagementUnsupported, http.StatusNotImplemented, CodeNotImplemented},
+}
+
+// WriteError writes an error envelope
+func WriteError(w http.ResponseWriter, status int, code, message string, details interface{}) {
+	writeJSON(w, status, ErrorBody{Code: code, Message: message, Details: details})
+}
+
+// WriteInvalidParameter writes the envelope of a malformed path or query parameter
+func WriteInvalidParameter(w http.ResponseWriter, name, value, reason string) {
+	WriteError(w, http.StatusBadRequest, CodeInvalidParameter, "Invalid "+name+": "+reason, map[string]string{
+		"parameter": name,
+		"value":     value,
+	})
+}
+
+// writeUseCaseError writes the envelope of an error returned by a use case;
+// unexpected errors are logged and reported without their text
+func writeUseCaseError(w http.ResponseWriter, logger *slog.Logger, err error, msg string, args ...any) {
+	var cfgErr *config.Error
+	if errors.As(err, &cfgErr) {
+		WriteError(w, http.StatusBadRequest, CodeInvalidConfig, "Invalid configuration", map[string]interface{}{
+			"file":     cfgErr.File,
+			"problems": cfgErr.Problems,
+		})
+		return
+	}
+
+	for _, known := range errorStatuses {
+		if errors.Is(err, known.err) {
+			WriteError(w, known.status, known.code, err.Error(), nil)
+			return
+		}
+	}
+
+	logger.Error(msg, append([]any{"error", err}, args...)...)
+	WriteError(w, http.StatusInternalServerError, CodeInternal, "Internal server error", nil)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
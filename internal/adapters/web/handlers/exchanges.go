@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"marketflow/internal/application/usecases"
+	"marketflow/internal/config"
+)
+
+// ExchangesHandler handles runtime management of live exchange feeds
+type ExchangesHandler struct {
+	dataProcessingUseCase *usecases.DataProcessingUseCase
+	logger                *slog.Logger
+}
+
+// NewExchangesHandler creates a new exchanges handler
+func NewExchangesHandler(dataProcessingUseCase *usecases.DataProcessingUseCase, logger *slog.Logger) *ExchangesHandler {
+	return &ExchangesHandler{
+		dataProcessingUseCase: dataProcessingUseCase,
+		logger:                logger,
+	}
+}
+
+// List handles GET /exchanges
+func (h *ExchangesHandler) List(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"exchanges": h.dataProcessingUseCase.ListExchanges(),
+	})
+}
+
+// Add handles POST /exchanges
+func (h *ExchangesHandler) Add(w http.ResponseWriter, r *http.Request) {
+	var cfg config.ExchangeConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		WriteError(w, http.StatusBadRequest, CodeInvalidBody, "Invalid exchange definition: "+err.Error(), nil)
+		return
+	}
+
+	if err := h.dataProcessingUseCase.AddExchange(cfg); err != nil {
+		writeUseCaseError(w, h.logger, err, "Failed to add exchange", "exchange", cfg.Name)
+		return
+	}
+	h.logger.Info("Exchange added", "exchange", cfg.Name, "host", cfg.Host, "port", cfg.Port)
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":   "success",
+		"exchange": cfg.Name,
+		"message":  "Exchange added",
+	})
+}
+
+// Remove handles DELETE /exchanges/{name}
+func (h *ExchangesHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	h.apply(w, r.PathValue("name"), "removed", h.dataProcessingUseCase.RemoveExchange)
+}
+
+// Pause handles POST /exchanges/{name}/pause
+func (h *ExchangesHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.apply(w, r.PathValue("name"), "paused", h.dataProcessingUseCase.PauseExchange)
+}
+
+// Resume handles POST /exchanges/{name}/resume
+func (h *ExchangesHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.apply(w, r.PathValue("name"), "resumed", h.dataProcessingUseCase.ResumeExchange)
+}
+
+func (h *ExchangesHandler) apply(w http.ResponseWriter, name, action string, change func(string) error) {
+	if err := change(name); err != nil {
+		writeUseCaseError(w, h.logger, err, "Failed to manage exchange", "exchange", name)
+		return
+	}
+	h.logger.Info("Exchange "+action, "exchange", name)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "success",
+		"exchange": name,
+		"message":  "Exchange " + action,
+	})
+}
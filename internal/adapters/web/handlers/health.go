@@ -1,41 +1,143 @@
 package handlers
 
 import (
-	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
+
+	"marketflow/internal/application/usecases"
+	"marketflow/internal/domain/models"
 )
 
 // HealthHandler handles health check requests
 type HealthHandler struct {
-	logger *slog.Logger
+	dataProcessingUseCase *usecases.DataProcessingUseCase
+	critical              map[string]bool
+	logger                *slog.Logger
 }
 
-// NewHealthHandler creates a new health handler
-func NewHealthHandler(logger *slog.Logger) *HealthHandler {
-	return &HealthHandler{
-		logger: logger,
+// NewHealthHandler creates a new health handler; the service is reported
+// unhealthy and not ready while any of the critical dependencies is down
+func NewHealthHandler(dataProcessingUseCase *usecases.DataProcessingUseCase, critical []string, logger *slog.Logger) *HealthHandler {
+	h := &HealthHandler{
+		dataProcessingUseCase: dataProcessingUseCase,
+		critical:              make(map[string]bool, len(critical)),
+		logger:                logger,
+	}
+	for _, name := range critical {
+		h.critical[name] = true
 	}
+	return h
 }
 
 // Handle handles health check requests
 func (h *HealthHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+	status := "healthy"
+	exchangeStatus := "connected"
+	if !h.dataProcessingUseCase.IsExchangeConnected() {
+		status = "degraded"
+		exchangeStatus = "disconnected"
+	}
+	if h.dataProcessingUseCase.GetFailoverStatus().Active {
+		status = "degraded"
+		exchangeStatus = "failover"
+	}
+
+	services := map[string]string{
+		"exchange": exchangeStatus,
+	}
+	dependencies, ready := h.dependencies()
+	for _, dependency := range dependencies {
+		if dependency.State != models.DependencyUp {
+			status = "degraded"
+		}
+		if service, ok := serviceNames[dependency.Name]; ok {
+			services[service] = connectionStatus(dependency.State)
+		}
+	}
+
+	code := http.StatusOK
+	if !ready {
+		status = "unhealthy"
+		code = http.StatusServiceUnavailable
 	}
 
 	response := map[string]interface{}{
-		"status":    "healthy",
-		"timestamp": r.Context().Value("timestamp"),
-		"services": map[string]string{
-			"database": "connected",
-			"cache":    "connected",
-			"exchange": "connected",
-		},
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+		"status":       status,
+		"timestamp":    time.Now(),
+		"services":     services,
+		"dependencies": dependencies,
+		"exchanges":    h.dataProcessingUseCase.GetExchangeStatuses(),
+	}
+
+	writeHealth(w, code, response)
+}
+
+// HandleLive reports that the process is up and serving requests
+func (h *HealthHandler) HandleLive(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, map[string]interface{}{
+		"status":    "alive",
+		"timestamp": time.Now(),
+	})
+}
+
+// HandleReady reports whether every critical dependency is up, with 503 otherwise
+func (h *HealthHandler) HandleReady(w http.ResponseWriter, r *http.Request) {
+	dependencies, ready := h.dependencies()
+
+	failing := []models.DependencyHealth{}
+	for _, dependency := range dependencies {
+		if dependency.Critical && dependency.State != models.DependencyUp {
+			failing = append(failing, dependency)
+		}
+	}
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+
+	writeHealth(w, code, map[string]interface{}{
+		"status":    status,
+		"timestamp": time.Now(),
+		"failing":   failing,
+	})
+}
+
+// dependencies returns the health of every dependency, marked critical as
+// configured, and whether all critical ones are up
+func (h *HealthHandler) dependencies() ([]models.DependencyHealth, bool) {
+	dependencies := h.dataProcessingUseCase.GetDependencyHealth()
+
+	ready := true
+	for i := range dependencies {
+		dependencies[i].Critical = h.critical[dependencies[i].Name]
+		if dependencies[i].Critical && dependencies[i].State != models.DependencyUp {
+			ready = false
+		}
+	}
+	return dependencies, ready
+}
+
+func writeHealth(w http.ResponseWriter, code int, response map[string]interface{}) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, code, response)
+}
+
+// serviceNames maps dependency names to the keys of the services summary
+var serviceNames = map[string]string{
+	"postgres": "database",
+	"redis":    "cache",
+}
+
+// connectionStatus describes a dependency state in the words of the services summary
+func connectionStatus(state models.DependencyState) string {
+	switch state {
+	case models.DependencyUp:
+		return "connected"
+	case models.DependencyDown:
+		return "disconnected"
+	default:
+		return string(state)
+	}
 }
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"marketflow/internal/application/usecases"
+)
+
+// HistoryHandler handles aggregated history requests
+type HistoryHandler struct {
+	marketDataUseCase *usecases.MarketDataUseCase
+	logger            *slog.Logger
+}
+
+// NewHistoryHandler creates a new history handler
+func NewHistoryHandler(marketDataUseCase *usecases.MarketDataUseCase, logger *slog.Logger) *HistoryHandler {
+	return &HistoryHandler{
+		marketDataUseCase: marketDataUseCase,
+		logger:            logger,
+	}
+}
+
+// Handle handles GET /history/{symbol}?exchange=&from=&to=&limit=&cursor=
+func (h *HistoryHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	symbol := r.PathValue("symbol")
+
+	query := r.URL.Query()
+	exchange := query.Get("exchange")
+	cursor := query.Get("cursor")
+
+	// Omitted times are resolved by the use case, or taken from the cursor
+	var from, to time.Time
+	if value := query.Get("to"); value != "" {
+		parsed, err := parseTime(value)
+		if err != nil {
+			WriteInvalidParameter(w, "to", value, err.Error())
+			return
+		}
+		to = parsed
+	}
+
+	if value := query.Get("from"); value != "" {
+		parsed, err := parseTime(value)
+		if err != nil {
+			WriteInvalidParameter(w, "from", value, err.Error())
+			return
+		}
+		from = parsed
+	}
+
+	limit := usecases.DefaultHistoryLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			WriteInvalidParameter(w, "limit", value, "expected a number")
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := h.marketDataUseCase.GetHistory(r.Context(), symbol, exchange, from, to, limit, cursor)
+	if err != nil {
+		writeUseCaseError(w, h.logger, err, "Failed to get history", "symbol", symbol, "exchange", exchange)
+		return
+	}
+
+	response := map[string]interface{}{
+		"symbol":   symbol,
+		"exchange": exchange,
+		"from":     page.From,
+		"to":       page.To,
+		"limit":    limit,
+		"data":     page.Data,
+	}
+	if page.NextCursor != "" {
+		response["next_cursor"] = page.NextCursor
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -1,10 +1,8 @@
 package handlers
 
 import (
-	"encoding/json"
 	"log/slog"
 	"net/http"
-	"strings"
 
 	"marketflow/internal/application/usecases"
 	"marketflow/internal/domain/models"
@@ -24,18 +22,9 @@ func NewModeHandler(dataProcessingUseCase *usecases.DataProcessingUseCase, logge
 	}
 }
 
-// Handle handles mode switching requests
+// Handle handles POST /mode/{mode}
 func (h *ModeHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	h.logger.Info("Mode handler called", "method", r.Method, "path", r.URL.Path)
-
-	if r.Method != http.MethodPost {
-		h.logger.Warn("Invalid method for mode endpoint", "method", r.Method)
-		w.Header().Set("Allow", "POST")
-		http.Error(w, "Method not allowed. Use POST.", http.StatusMethodNotAllowed)
-		return
-	}
-
-	path := strings.TrimPrefix(r.URL.Path, "/mode/")
+	path := r.PathValue("mode")
 	h.logger.Info("Processing mode switch", "requested_mode", path)
 
 	var mode models.DataMode
@@ -46,7 +35,7 @@ func (h *ModeHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		mode = models.DataModeTest
 	default:
 		h.logger.Warn("Invalid mode requested", "mode", path)
-		http.Error(w, "Invalid mode. Use 'live' or 'test'.", http.StatusBadRequest)
+		WriteInvalidParameter(w, "mode", path, "must be one of live, test")
 		return
 	}
 
@@ -59,7 +48,5 @@ func (h *ModeHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		"message": "Data mode switched successfully",
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	writeJSON(w, http.StatusOK, response)
 }
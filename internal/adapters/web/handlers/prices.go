@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -9,6 +8,7 @@ import (
 	"time"
 
 	"marketflow/internal/application/usecases"
+	"marketflow/internal/domain/models"
 )
 
 // PricesHandler handles price-related requests
@@ -25,78 +25,56 @@ func NewPricesHandler(marketDataUseCase *usecases.MarketDataUseCase, logger *slo
 	}
 }
 
-// Handle handles price requests
+// Handle handles GET /prices/{operation}/{symbol} and /prices/{operation}/{exchange}/{symbol}?period=
 func (h *PricesHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	path := strings.TrimPrefix(r.URL.Path, "/prices/")
-	parts := strings.Split(path, "/")
-
-	if len(parts) < 2 {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
-		return
-	}
-
-	operation := parts[0]
-
-	var exchange, symbol string
-	var period time.Duration
-
-	// Parse path parameters
-	if len(parts) == 2 {
-		symbol = parts[1]
-	} else if len(parts) == 3 {
-		exchange = parts[1]
-		symbol = parts[2]
-	}
+	operation := r.PathValue("operation")
+	exchange := r.PathValue("exchange")
+	symbol := r.PathValue("symbol")
 
 	// Parse period from query parameters
+	period := time.Minute // default period
 	if periodStr := r.URL.Query().Get("period"); periodStr != "" {
 		var err error
 		period, err = parsePeriod(periodStr)
 		if err != nil {
-			http.Error(w, "Invalid period format", http.StatusBadRequest)
+			WriteInvalidParameter(w, "period", periodStr, "expected a duration such as 30s or 5m")
 			return
 		}
-	} else {
-		period = time.Minute // default period
 	}
 
 	ctx := r.Context()
-	var response interface{}
+	var latest *models.LatestPrice
+	var data *models.AggregatedData
 	var err error
 
 	switch operation {
 	case "latest":
-		response, err = h.marketDataUseCase.GetLatestPrice(ctx, symbol, exchange)
+		latest, err = h.marketDataUseCase.GetLatestPrice(ctx, symbol, exchange)
 	case "highest":
-		response, err = h.marketDataUseCase.GetHighestPrice(ctx, symbol, exchange, period)
+		data, err = h.marketDataUseCase.GetHighestPrice(ctx, symbol, exchange, period)
 	case "lowest":
-		response, err = h.marketDataUseCase.GetLowestPrice(ctx, symbol, exchange, period)
+		data, err = h.marketDataUseCase.GetLowestPrice(ctx, symbol, exchange, period)
 	case "average":
-		response, err = h.marketDataUseCase.GetAveragePrice(ctx, symbol, exchange, period)
+		data, err = h.marketDataUseCase.GetAveragePrice(ctx, symbol, exchange, period)
 	default:
-		http.Error(w, "Unknown operation", http.StatusBadRequest)
+		WriteInvalidParameter(w, "operation", operation, "must be one of latest, highest, lowest, average")
 		return
 	}
 
 	if err != nil {
-		h.logger.Error("Failed to process request", "error", err, "operation", operation)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeUseCaseError(w, h.logger, err, "Failed to process request", "operation", operation)
 		return
 	}
 
-	if response == nil {
-		http.Error(w, "Not found", http.StatusNotFound)
-		return
+	// Check the pointers themselves: a nil pointer stored in an interface is not nil
+	switch {
+	case latest != nil:
+		writeJSON(w, http.StatusOK, latest)
+	case data != nil:
+		writeJSON(w, http.StatusOK, data)
+	default:
+		WriteError(w, http.StatusNotFound, CodeNotFound, "No price data for "+symbol, nil)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
 }
 
 func parsePeriod(periodStr string) (time.Duration, error) {
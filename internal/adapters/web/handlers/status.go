@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"log/slog"
 	"net/http"
 
@@ -24,20 +23,20 @@ func NewStatusHandler(dataProcessingUseCase *usecases.DataProcessingUseCase, log
 
 // Handle handles status requests
 func (h *StatusHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	currentMode := h.dataProcessingUseCase.GetMode()
 
 	response := map[string]interface{}{
-		"current_mode": string(currentMode),
+		"current_mode":    string(currentMode),
 		"available_modes": []string{"live", "test"},
-		"status": "running",
+		"status":          "running",
+		"connected":       h.dataProcessingUseCase.IsExchangeConnected(),
+		"exchanges":       h.dataProcessingUseCase.GetExchangeStatuses(),
+		"failover":        h.dataProcessingUseCase.GetFailoverStatus(),
+		"worker_pools":    h.dataProcessingUseCase.GetWorkerPoolSizes(),
+		"lateness":        h.dataProcessingUseCase.GetLatenessStats(),
+		"spool":           h.dataProcessingUseCase.GetSpoolStatus(),
+		"stream":          h.dataProcessingUseCase.GetStreamStats(),
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	writeJSON(w, http.StatusOK, response)
 }
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"marketflow/internal/application/usecases"
+	"marketflow/internal/concurrency"
+)
+
+const (
+	// streamWriteTimeout bounds a single write to a stream client, so a client
+	// that stopped reading is disconnected instead of holding its handler
+	streamWriteTimeout = 10 * time.Second
+
+	// streamRetry is the reconnection delay suggested to clients, in milliseconds
+	streamRetry = 3000
+)
+
+// StreamHandler streams live price updates as Server-Sent Events
+type StreamHandler struct {
+	marketDataUseCase *usecases.MarketDataUseCase
+	heartbeat         time.Duration
+	logger            *slog.Logger
+}
+
+// NewStreamHandler creates a new stream handler that sends a keep-alive comment every heartbeat
+func NewStreamHandler(marketDataUseCase *usecases.MarketDataUseCase, heartbeat time.Duration, logger *slog.Logger) *StreamHandler {
+	return &StreamHandler{
+		marketDataUseCase: marketDataUseCase,
+		heartbeat:         heartbeat,
+		logger:            logger,
+	}
+}
+
+// Handle handles GET /stream/prices?symbols=&exchanges=
+func (h *StreamHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	symbols := splitList(query.Get("symbols"))
+	exchanges := splitList(query.Get("exchanges"))
+
+	// Browsers send Last-Event-ID when reconnecting; the query parameter
+	// lets a new EventSource resume too
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("last_event_id")
+	}
+	var lastID uint64
+	resume := lastEventID != ""
+	if resume {
+		parsed, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			WriteInvalidParameter(w, "last_event_id", lastEventID, "expected an event id")
+			return
+		}
+		lastID = parsed
+	}
+
+	sub, backlog, err := h.marketDataUseCase.SubscribePrices(symbols, exchanges, lastID, resume)
+	if err != nil {
+		writeUseCaseError(w, h.logger, err, "Failed to subscribe to prices")
+		return
+	}
+	defer sub.Close()
+
+	h.logger.Info("Stream client connected", "remote", r.RemoteAddr,
+		"symbols", symbols, "exchanges", exchanges, "replayed", len(backlog))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &eventWriter{w: w, rc: http.NewResponseController(w)}
+	stream.printf("retry: %d\n\n", streamRetry)
+	for _, event := range backlog {
+		stream.event(event)
+	}
+	if err := stream.flush(); err != nil {
+		h.logger.Debug("Stream client gone", "error", err, "remote", r.RemoteAddr)
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			h.logger.Info("Stream client disconnected", "remote", r.RemoteAddr)
+			return
+
+		case event, ok := <-sub.Events():
+			if !ok {
+				h.logger.Warn("Stream client evicted", "error", sub.Err(), "remote", r.RemoteAddr)
+				stream.printf("event: evicted\ndata: %s\n\n", mustJSON(map[string]string{"reason": errorString(sub.Err())}))
+				stream.flush()
+				return
+			}
+			stream.event(event)
+
+		case <-heartbeat.C:
+			stream.printf(": heartbeat\n\n")
+		}
+
+		if err := stream.flush(); err != nil {
+			h.logger.Info("Stream client gone", "error", err, "remote", r.RemoteAddr)
+			return
+		}
+	}
+}
+
+// eventWriter writes Server-Sent Events, keeping the first error
+type eventWriter struct {
+	w   io.Writer
+	rc  *http.ResponseController
+	err error
+}
+
+func (s *eventWriter) printf(format string, args ...interface{}) {
+	if s.err != nil {
+		return
+	}
+	s.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	_, s.err = fmt.Fprintf(s.w, format, args...)
+}
+
+func (s *eventWriter) event(event concurrency.Event) {
+	s.printf("id: %d\nevent: price\ndata: %s\n\n", event.ID, mustJSON(event.Update))
+}
+
+func (s *eventWriter) flush() error {
+	if s.err != nil {
+		return s.err
+	}
+	s.err = s.rc.Flush()
+	return s.err
+}
+
+// splitList splits a comma-separated query value, ignoring empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func mustJSON(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
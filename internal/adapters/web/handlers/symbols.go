@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"marketflow/internal/application/usecases"
+	"marketflow/internal/domain/models"
+	"marketflow/internal/domain/symbols"
+)
+
+// SymbolsHandler handles management of the tracked symbol registry
+type SymbolsHandler struct {
+	symbolsUseCase *usecases.SymbolsUseCase
+	logger         *slog.Logger
+}
+
+// NewSymbolsHandler creates a new symbols handler
+func NewSymbolsHandler(symbolsUseCase *usecases.SymbolsUseCase, logger *slog.Logger) *SymbolsHandler {
+	return &SymbolsHandler{
+		symbolsUseCase: symbolsUseCase,
+		logger:         logger,
+	}
+}
+
+// symbolRequest is the body of POST /admin/symbols; precision is optional
+type symbolRequest struct {
+	Name      string `json:"name"`
+	Base      string `json:"base"`
+	Quote     string `json:"quote"`
+	Precision *int   `json:"precision"`
+}
+
+// List handles GET /admin/symbols
+func (h *SymbolsHandler) List(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"symbols": h.symbolsUseCase.ListSymbols(),
+	})
+}
+
+// Get handles GET /admin/symbols/{symbol}
+func (h *SymbolsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("symbol")
+	symbol, ok := h.symbolsUseCase.GetSymbol(name)
+	if !ok {
+		WriteError(w, http.StatusNotFound, CodeUnknownSymbol, "Unknown symbol "+name, nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, symbol)
+}
+
+// Remove handles DELETE /admin/symbols/{symbol}
+func (h *SymbolsHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("symbol")
+	if err := h.symbolsUseCase.RemoveSymbol(name); err != nil {
+		writeUseCaseError(w, h.logger, err, "Failed to remove symbol", "symbol", name)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"symbol": name,
+		"status": "removed",
+	})
+}
+
+// Add handles POST /admin/symbols
+func (h *SymbolsHandler) Add(w http.ResponseWriter, r *http.Request) {
+	var req symbolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, CodeInvalidBody, "Invalid request body: "+err.Error(), nil)
+		return
+	}
+
+	symbol := models.Symbol{
+		Name:      req.Name,
+		Base:      req.Base,
+		Quote:     req.Quote,
+		Precision: symbols.DefaultPrecision,
+	}
+	if req.Precision != nil {
+		symbol.Precision = *req.Precision
+	}
+
+	added, err := h.symbolsUseCase.AddSymbol(symbol)
+	if err != nil {
+		writeUseCaseError(w, h.logger, err, "Failed to add symbol", "symbol", req.Name)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, added)
+}
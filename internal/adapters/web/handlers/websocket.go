@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"marketflow/internal/application/usecases"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+)
+
+const (
+	// wsMaxMessageSize bounds a single client message
+	wsMaxMessageSize = 4096
+
+	// wsWriteTimeout bounds a single write to a client
+	wsWriteTimeout = 10 * time.Second
+)
+
+// WebSocketHandler serves the subscription API: clients subscribe to the
+// ticks, candles and spreads of some symbols and exchanges over one connection
+type WebSocketHandler struct {
+	marketDataUseCase *usecases.MarketDataUseCase
+	cfg               config.WebSocketConfig
+	upgrader          websocket.Upgrader
+	logger            *slog.Logger
+}
+
+// NewWebSocketHandler creates a new WebSocket handler
+func NewWebSocketHandler(marketDataUseCase *usecases.MarketDataUseCase, cfg config.WebSocketConfig, logger *slog.Logger) *WebSocketHandler {
+	h := &WebSocketHandler{
+		marketDataUseCase: marketDataUseCase,
+		cfg:               cfg,
+		logger:            logger,
+	}
+	h.upgrader = websocket.Upgrader{
+		CheckOrigin: h.checkOrigin,
+		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			WriteError(w, status, CodeHandshakeFailed, reason.Error(), nil)
+		},
+	}
+	return h
+}
+
+// checkOrigin accepts clients that are not browsers, pages served from this
+// API's own host and pages from one of the configured allowed origins
+func (h *WebSocketHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range h.cfg.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	u, err := url.Parse(origin)
+	if err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	h.logger.Debug("WebSocket origin not allowed", "origin", origin)
+	return false
+}
+
+// wsRequest is a message sent by a client
+type wsRequest struct {
+	Op        string                 `json:"op"` // subscribe, unsubscribe or ping
+	ID        string                 `json:"id,omitempty"`
+	Channels  []models.StreamChannel `json:"channels"`
+	Symbols   []string               `json:"symbols"`
+	Exchanges []string               `json:"exchanges"`
+}
+
+// wsResponse is a message sent to a client
+type wsResponse struct {
+	Type          string                 `json:"type"` // welcome, ack, error, pong or data
+	Op            string                 `json:"op,omitempty"`
+	ID            string                 `json:"id,omitempty"`
+	Message       string                 `json:"message,omitempty"`
+	Channel       models.StreamChannel   `json:"channel,omitempty"`
+	Data          interface{}            `json:"data,omitempty"`
+	Channels      []models.StreamChannel `json:"channels,omitempty"`
+	Subscriptions []wsSubscription       `json:"subscriptions,omitempty"`
+}
+
+// wsSubscription describes what a connection receives on a channel; empty lists mean all
+type wsSubscription struct {
+	Channel   models.StreamChannel `json:"channel"`
+	Symbols   []string             `json:"symbols"`
+	Exchanges []string             `json:"exchanges"`
+}
+
+// Handle handles GET /ws
+func (h *WebSocketHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	// The upgrader answers failed handshakes itself
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Debug("WebSocket upgrade failed", "error", err, "remote", r.RemoteAddr)
+		return
+	}
+
+	client := &wsClient{
+		handler: h,
+		conn:    conn,
+		send:    make(chan []byte, h.cfg.SendQueue),
+		done:    make(chan struct{}),
+		full:    make(chan struct{}),
+		topics:  make(map[models.StreamChannel]*wsTopic),
+		logger:  h.logger.With("remote", r.RemoteAddr),
+	}
+	client.run()
+}
+
+// wsTopic is the symbol and exchange filter of one subscribed channel
+type wsTopic struct {
+	symbols   map[string]bool // nil means all symbols
+	exchanges map[string]bool // nil means all exchanges
+}
+
+// wsClient is one WebSocket connection
+type wsClient struct {
+	handler *WebSocketHandler
+	conn    *websocket.Conn
+	send    chan []byte   // per-connection queue drained by the writer
+	done    chan struct{} // closed when the reader stops
+	full    chan struct{} // closed when the send queue overflows
+	once    sync.Once
+	logger  *slog.Logger
+
+	mu     sync.RWMutex
+	topics map[models.StreamChannel]*wsTopic
+}
+
+func (c *wsClient) run() {
+	c.logger.Info("WebSocket client connected")
+
+	stop := c.handler.marketDataUseCase.ListenLiveFeed(c.deliver)
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		c.writeLoop()
+	}()
+
+	c.enqueue(wsResponse{Type: "welcome", Channels: models.StreamChannels})
+	c.readLoop()
+
+	stop()
+	close(c.done)
+	<-writerDone
+	c.conn.Close()
+	c.logger.Info("WebSocket client disconnected")
+}
+
+// readLoop handles client messages until the connection fails or is closed
+func (c *wsClient) readLoop() {
+	pongWait := 2 * c.handler.cfg.PingInterval.Std()
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var request wsRequest
+		if err := c.conn.ReadJSON(&request); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				c.enqueue(wsResponse{Type: "error", Message: "invalid message: " + err.Error()})
+				continue
+			}
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				c.logger.Debug("WebSocket read failed", "error", err)
+			}
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		c.enqueue(c.handle(request))
+	}
+}
+
+// handle applies a client request and returns the reply
+func (c *wsClient) handle(request wsRequest) wsResponse {
+	switch request.Op {
+	case "ping":
+		return wsResponse{Type: "pong", ID: request.ID}
+	case "subscribe", "unsubscribe":
+	default:
+		return wsResponse{Type: "error", ID: request.ID, Message: fmt.Sprintf("unknown op %q: must be subscribe, unsubscribe or ping", request.Op)}
+	}
+
+	if len(request.Channels) == 0 {
+		return wsResponse{Type: "error", Op: request.Op, ID: request.ID, Message: "channels is required"}
+	}
+	for _, channel := range request.Channels {
+		if !slices.Contains(models.StreamChannels, channel) {
+			return wsResponse{Type: "error", Op: request.Op, ID: request.ID, Message: fmt.Sprintf("unknown channel %q: must be one of %v", channel, models.StreamChannels)}
+		}
+	}
+	if err := c.handler.marketDataUseCase.CheckStreamFilter(request.Symbols, request.Exchanges); err != nil {
+		return wsResponse{Type: "error", Op: request.Op, ID: request.ID, Message: err.Error()}
+	}
+
+	if request.Op == "subscribe" {
+		c.subscribe(request)
+	} else {
+		c.unsubscribe(request)
+	}
+	return wsResponse{Type: "ack", Op: request.Op, ID: request.ID, Subscriptions: c.subscriptions()}
+}
+
+// subscribe adds symbols and exchanges to channels; empty lists subscribe to all of them
+func (c *wsClient) subscribe(request wsRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, channel := range request.Channels {
+		topic, ok := c.topics[channel]
+		if !ok {
+			topic = &wsTopic{symbols: map[string]bool{}, exchanges: map[string]bool{}}
+			c.topics[channel] = topic
+		}
+		topic.symbols = addAll(topic.symbols, request.Symbols)
+		topic.exchanges = addAll(topic.exchanges, request.Exchanges)
+	}
+}
+
+// unsubscribe removes symbols from channels, or whole channels when no symbols
+// are given or the channel was subscribed for all symbols
+func (c *wsClient) unsubscribe(request wsRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, channel := range request.Channels {
+		topic, ok := c.topics[channel]
+		if !ok {
+			continue
+		}
+		if len(request.Symbols) == 0 || topic.symbols == nil {
+			delete(c.topics, channel)
+			continue
+		}
+		for _, symbol := range request.Symbols {
+			delete(topic.symbols, symbol)
+		}
+		if len(topic.symbols) == 0 {
+			delete(c.topics, channel)
+		}
+	}
+}
+
+func (c *wsClient) subscriptions() []wsSubscription {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	subscriptions := []wsSubscription{}
+	for _, channel := range models.StreamChannels {
+		topic, ok := c.topics[channel]
+		if !ok {
+			continue
+		}
+		subscriptions = append(subscriptions, wsSubscription{
+			Channel:   channel,
+			Symbols:   sortedKeys(topic.symbols),
+			Exchanges: sortedKeys(topic.exchanges),
+		})
+	}
+	return subscriptions
+}
+
+// deliver queues a live feed message the connection subscribed to; it runs on
+// the feed's goroutine and never blocks
+func (c *wsClient) deliver(message models.StreamMessage) {
+	if !c.wants(message) {
+		return
+	}
+	c.enqueue(wsResponse{Type: "data", Channel: message.Channel, Data: message.Data})
+}
+
+func (c *wsClient) wants(message models.StreamMessage) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	topic, ok := c.topics[message.Channel]
+	if !ok {
+		return false
+	}
+	if topic.symbols != nil && !topic.symbols[message.Symbol] {
+		return false
+	}
+	// Spreads span exchanges and are not filtered by exchange
+	return message.Exchange == "" || topic.exchanges == nil || topic.exchanges[message.Exchange]
+}
+
+// enqueue adds a message to the send queue; a connection whose queue is full
+// is closed rather than slowing down the feed
+func (c *wsClient) enqueue(response wsResponse) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		c.logger.Error("Failed to encode WebSocket message", "error", err)
+		return
+	}
+
+	select {
+	case c.send <- data:
+	default:
+		c.once.Do(func() { close(c.full) })
+	}
+}
+
+// writeLoop sends queued messages and pings until the reader stops or the queue overflows
+func (c *wsClient) writeLoop() {
+	ping := time.NewTicker(c.handler.cfg.PingInterval.Std())
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			c.close(websocket.CloseNormalClosure, "")
+			return
+
+		case <-c.full:
+			c.logger.Warn("WebSocket send queue full, closing connection", "send_queue", cap(c.send))
+			c.close(websocket.CloseTryAgainLater, "send queue full")
+			return
+
+		case data := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				c.logger.Debug("WebSocket write failed", "error", err)
+				c.conn.Close() // unblocks the reader
+				return
+			}
+
+		case <-ping.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				c.logger.Debug("WebSocket ping failed", "error", err)
+				c.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// close sends a close frame and closes the connection, which stops the reader
+func (c *wsClient) close(code int, reason string) {
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteTimeout))
+	c.conn.Close()
+}
+
+// addAll adds values to set; a nil set already holds everything, and no values means everything
+func addAll(set map[string]bool, values []string) map[string]bool {
+	if set == nil || len(values) == 0 {
+		return nil
+	}
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// sortedKeys returns the members of set in order, or an empty list for a nil set meaning all
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"marketflow/internal/config"
+)
+
+func TestWebSocketCheckOrigin(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	cases := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{"no origin", nil, "", true},
+		{"same host", nil, "http://api.example.com:8080", true},
+		{"other site", nil, "https://dashboard.example.com", false},
+		{"allowed site", []string{"https://dashboard.example.com"}, "https://dashboard.example.com", true},
+		{"allowed site in other case", []string{"https://Dashboard.example.com"}, "https://dashboard.example.com", true},
+		{"other scheme", []string{"https://dashboard.example.com"}, "http://dashboard.example.com", false},
+		{"other port", []string{"https://dashboard.example.com"}, "https://dashboard.example.com:8443", false},
+		{"any site", []string{"*"}, "https://dashboard.example.com", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := NewWebSocketHandler(nil, config.WebSocketConfig{AllowedOrigins: c.allowed}, logger)
+			req := httptest.NewRequest("GET", "http://api.example.com:8080/ws", nil)
+			if c.origin != "" {
+				req.Header.Set("Origin", c.origin)
+			}
+			if got := h.upgrader.CheckOrigin(req); got != c.want {
+				t.Fatalf("origin %q allowed %v, want %v", c.origin, got, c.want)
+			}
+		})
+	}
+}
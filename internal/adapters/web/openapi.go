@@ -0,0 +1,17 @@
+package web
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec describes every route of the server
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openAPISpec)
+}
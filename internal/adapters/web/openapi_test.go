@@ -0,0 +1,813 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"marketflow/internal/adapters/web/handlers"
+	"marketflow/internal/application/ports"
+	"marketflow/internal/application/usecases"
+	"marketflow/internal/concurrency"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+	"marketflow/internal/domain/symbols"
+)
+
+// decodeOpenAPI returns the embedded document decoded into generic JSON values
+func decodeOpenAPI(t *testing.T) map[string]interface{} {
+	t.Helper()
+	var doc map[string]interface{}
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("parse openapi.json: %v", err)
+	}
+	return doc
+}
+
+// TestOpenAPIDescribesEveryRoute checks that every route is described with its
+// path parameters, every described operation is routed and every reference resolves
+func TestOpenAPIDescribesEveryRoute(t *testing.T) {
+	doc := decodeOpenAPI(t)
+	if version, _ := doc["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		t.Errorf("openapi: expected version 3.x, got %q", version)
+	}
+
+	// The handlers only need their dependencies when serving, so the routes
+	// can be listed without any
+	server := &Server{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	routed := make(map[string]bool)
+	for _, route := range server.routes().Routes() {
+		key := route.Method + " " + route.Pattern
+		routed[key] = true
+
+		operation, ok := lookup(doc, "paths", route.Pattern, strings.ToLower(route.Method))
+		if !ok {
+			t.Errorf("%s: route is not described", key)
+			continue
+		}
+		if responses, _ := lookup(operation, "responses"); len(responses.(map[string]interface{})) == 0 {
+			t.Errorf("%s: no responses described", key)
+		}
+
+		shared, _ := lookup(doc, "paths", route.Pattern, "parameters")
+		own, _ := lookup(operation, "parameters")
+		parameters, _ := shared.([]interface{})
+		if own, ok := own.([]interface{}); ok {
+			parameters = append(append([]interface{}{}, parameters...), own...)
+		}
+
+		var described []string
+		for _, parameter := range parameters {
+			if ref, ok := parameter.(map[string]interface{})["$ref"].(string); ok {
+				if parameter, ok = resolve(doc, ref); !ok {
+					continue // reported with the other references
+				}
+			}
+			if in, _ := lookup(parameter, "in"); in != "path" {
+				continue
+			}
+			name, _ := lookup(parameter, "name")
+			if required, _ := lookup(parameter, "required"); required != true {
+				t.Errorf("%s: path parameter %v must be required", key, name)
+			}
+			described = append(described, fmt.Sprint(name))
+		}
+
+		expected := patternParams(route.Pattern)
+		for _, name := range expected {
+			if !slices.Contains(described, name) {
+				t.Errorf("%s: path parameter %s is not described", key, name)
+			}
+		}
+		for _, name := range described {
+			if !slices.Contains(expected, name) {
+				t.Errorf("%s: path parameter %s is not in the route", key, name)
+			}
+		}
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	for path, item := range paths {
+		for method := range item.(map[string]interface{}) {
+			if key := strings.ToUpper(method) + " " + path; method != "parameters" && !routed[key] {
+				t.Errorf("%s: described but not routed", key)
+			}
+		}
+	}
+
+	for _, ref := range collectRefs(doc) {
+		if _, ok := resolve(doc, ref); !ok {
+			t.Errorf("%s: reference does not resolve", ref)
+		}
+	}
+}
+
+// TestOpenAPISchemasDescribeTheModels encodes a value of every model that is
+// not necessarily part of a response in TestRoutesMatchOpenAPI, such as the
+// stream payloads, with every field set, and checks it against its schema
+func TestOpenAPISchemasDescribeTheModels(t *testing.T) {
+	doc := decodeOpenAPI(t)
+	at := time.Date(2026, 1, 1, 12, 3, 0, 0, time.UTC)
+	enabled := true
+
+	samples := map[string]interface{}{
+		"PriceUpdate": models.PriceUpdate{Symbol: "BTCUSDT", Price: 100.5, Timestamp: at.UnixMilli(), Exchange: "exchange1", ReceivedAt: at},
+		"Candle": models.Candle{PairName: "BTCUSDT", Exchange: "exchange1", Interval: "1m", OpenTime: at, CloseTime: at.Add(time.Minute),
+			Open: 100, High: 102, Low: 99, Close: 101, TickCount: 4, FirstTickAt: at, LastTickAt: at.Add(59 * time.Second)},
+		"Spread": models.Spread{Symbol: "BTCUSDT", High: 101, HighExchange: "exchange1", Low: 100, LowExchange: "exchange2",
+			Spread: 1, SpreadBps: 100, Exchanges: 2, Timestamp: at},
+		"AggregatedData": models.AggregatedData{ID: 1, PairName: "BTCUSDT", Exchange: "exchange1", Timestamp: at, WindowStart: at,
+			WindowEnd: at.Add(time.Minute), Interval: "1m", AveragePrice: 100.5, MinPrice: 100, MaxPrice: 101, TickCount: 2},
+		"LatestPrice":        models.LatestPrice{Symbol: "BTCUSDT", Exchange: "exchange1", Price: 100.5, Timestamp: at},
+		"Symbol":             models.Symbol{Name: "BTCUSDT", Base: "BTC", Quote: "USDT", Precision: 2},
+		"ExchangeDefinition": config.ExchangeConfig{Name: "exchange1", Host: "127.0.0.1", Port: 40101, Enabled: &enabled, Symbols: []string{"BTCUSDT"}, Protocol: "json"},
+		"Error":              handlers.ErrorBody{Code: handlers.CodeInvalidParameter, Message: "Invalid symbol", Details: map[string]string{"parameter": "symbol"}},
+	}
+
+	for name, sample := range samples {
+		encoded, err := json.Marshal(sample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var value map[string]interface{}
+		if err := json.Unmarshal(encoded, &value); err != nil {
+			t.Fatal(err)
+		}
+
+		ref := "#/components/schemas/" + name
+		for _, problem := range validateSchema(doc, map[string]interface{}{"$ref": ref}, value, name) {
+			t.Error(problem)
+		}
+
+		// Every field is set, so a described property missing from the value has no field
+		properties, _ := lookup(doc, "components", "schemas", name, "properties")
+		for property := range properties.(map[string]interface{}) {
+			if _, ok := value[property]; !ok {
+				t.Errorf("%s: property %s has no field in %T", name, property, sample)
+			}
+		}
+	}
+}
+
+// collectRefs returns every $ref of a decoded JSON document
+func collectRefs(node interface{}) []string {
+	var refs []string
+	switch value := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := value["$ref"].(string); ok {
+			refs = append(refs, ref)
+		}
+		for _, child := range value {
+			refs = append(refs, collectRefs(child)...)
+		}
+	case []interface{}:
+		for _, child := range value {
+			refs = append(refs, collectRefs(child)...)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+func TestErrorCodesMatchOpenAPI(t *testing.T) {
+	doc := decodeOpenAPI(t)
+	node, ok := lookup(doc, "components", "schemas", "Error", "properties", "code", "enum")
+	if !ok {
+		t.Fatal("Error.code has no enum")
+	}
+	var documented []string
+	for _, code := range node.([]interface{}) {
+		documented = append(documented, code.(string))
+	}
+
+	codes := []string{
+		handlers.CodeNotFound, handlers.CodeMethodNotAllowed, handlers.CodeInternal,
+		handlers.CodeInvalidParameter, handlers.CodeInvalidBody, handlers.CodeInvalidConfig,
+		handlers.CodeHandshakeFailed, handlers.CodeNotImplemented,
+		handlers.CodeUnknownSymbol, handlers.CodeInvalidSymbol, handlers.CodeSymbolExists,
+		handlers.CodeUnknownExchange, handlers.CodeInvalidInterval, handlers.CodeInvalidRange,
+		handlers.CodeInvalidLimit, handlers.CodeInvalidCursor, handlers.CodeExchangeNotFound,
+		handlers.CodeExchangeExists, handlers.CodeInvalidExchange,
+	}
+	sort.Strings(codes)
+	sort.Strings(documented)
+	if !slices.Equal(codes, documented) {
+		t.Fatalf("error codes %v, documented %v", codes, documented)
+	}
+}
+
+// routeCase is one request and the status it must get; the body is checked
+// against the schema the document gives that status
+type routeCase struct {
+	method, pattern, path, body string
+	status                      int
+}
+
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	doc := decodeOpenAPI(t)
+	router := newTestServer(t).routes()
+
+	cases := []routeCase{
+		{"GET", "/prices/{operation}/{symbol}", "/prices/latest/BTCUSDT", "", 200},
+		{"GET", "/prices/{operation}/{symbol}", "/prices/average/BTCUSDT?period=5m", "", 200},
+		{"GET", "/prices/{operation}/{symbol}", "/prices/latest/ETHUSDT", "", 404},
+		{"GET", "/prices/{operation}/{symbol}", "/prices/highest/ETHUSDT", "", 404},
+		{"GET", "/prices/{operation}/{symbol}", "/prices/latest/DOGEUSDT", "", 404},
+		{"GET", "/prices/{operation}/{symbol}", "/prices/median/BTCUSDT", "", 400},
+		{"GET", "/prices/{operation}/{symbol}", "/prices/latest/btc", "", 400},
+		{"GET", "/prices/{operation}/{symbol}", "/prices/lowest/BTCUSDT?period=soon", "", 400},
+		{"GET", "/prices/{operation}/{exchange}/{symbol}", "/prices/highest/exchange1/BTCUSDT", "", 200},
+		{"GET", "/prices/{operation}/{exchange}/{symbol}", "/prices/latest/exchange1/BTCUSDT", "", 200},
+		{"GET", "/prices/{operation}/{exchange}/{symbol}", "/prices/latest/nowhere/BTCUSDT", "", 400},
+		{"GET", "/candles/{symbol}", "/candles/BTCUSDT", "", 200},
+		{"GET", "/candles/{symbol}", "/candles/BTCUSDT?interval=7m", "", 400},
+		{"GET", "/history/{symbol}", "/history/BTCUSDT?limit=1", "", 200},
+		{"GET", "/history/{symbol}", "/history/BTCUSDT?exchange=exchange1", "", 200},
+		{"GET", "/history/{symbol}", "/history/BTCUSDT?cursor=garbage", "", 400},
+		{"GET", "/history/{symbol}", "/history/BTCUSDT?limit=0", "", 400},
+		{"GET", "/stream/prices", "/stream/prices?symbols=BTCUSDT", "", 200},
+		{"GET", "/ws", "/ws", "", 400},
+		{"GET", "/health", "/health", "", 200},
+		{"GET", "/health/live", "/health/live", "", 200},
+		{"GET", "/health/ready", "/health/ready", "", 200},
+		{"GET", "/status", "/status", "", 200},
+		{"GET", "/backpressure", "/backpressure", "", 200},
+		{"GET", "/exchanges", "/exchanges", "", 200},
+		{"POST", "/exchanges", "/exchanges", `{"name":"exchange9","host":"127.0.0.1","port":40199}`, 201},
+		{"POST", "/exchanges", "/exchanges", `{"name":"exchange9","host":"127.0.0.1","port":40199}`, 409},
+		{"POST", "/exchanges", "/exchanges", `{"name":"exchange9"}`, 400},
+		{"POST", "/exchanges", "/exchanges", `{`, 400},
+		{"POST", "/exchanges/{name}/pause", "/exchanges/exchange9/pause", "", 200},
+		{"POST", "/exchanges/{name}/resume", "/exchanges/exchange9/resume", "", 200},
+		{"POST", "/exchanges/{name}/pause", "/exchanges/nowhere/pause", "", 404},
+		{"POST", "/exchanges/{name}/resume", "/exchanges/nowhere/resume", "", 404},
+		{"DELETE", "/exchanges/{name}", "/exchanges/exchange9", "", 200},
+		{"DELETE", "/exchanges/{name}", "/exchanges/exchange9", "", 404},
+		{"GET", "/admin/symbols", "/admin/symbols", "", 200},
+		{"POST", "/admin/symbols", "/admin/symbols", `{"name":"SOLUSDT"}`, 201},
+		{"POST", "/admin/symbols", "/admin/symbols", `{"name":"SOLUSDT"}`, 409},
+		{"POST", "/admin/symbols", "/admin/symbols", `{"name":"sol"}`, 400},
+		{"GET", "/admin/symbols/{symbol}", "/admin/symbols/SOLUSDT", "", 200},
+		{"DELETE", "/admin/symbols/{symbol}", "/admin/symbols/SOLUSDT", "", 200},
+		{"GET", "/admin/symbols/{symbol}", "/admin/symbols/SOLUSDT", "", 404},
+		{"DELETE", "/admin/symbols/{symbol}", "/admin/symbols/SOLUSDT", "", 404},
+		{"POST", "/admin/reload", "/admin/reload", "", 200},
+		{"POST", "/mode/{mode}", "/mode/test", "", 200},
+		{"POST", "/mode/{mode}", "/mode/paper", "", 400},
+		{"GET", "/openapi.json", "/openapi.json", "", 200},
+	}
+
+	covered := make(map[string]bool)
+	for _, c := range cases {
+		covered[c.method+" "+c.pattern] = true
+
+		t.Run(c.method+" "+c.path, func(t *testing.T) {
+			rec := serve(router, c)
+			if rec.Code != c.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, c.status, rec.Body)
+			}
+			for _, problem := range checkResponse(doc, c, rec) {
+				t.Error(problem)
+			}
+		})
+	}
+
+	for _, route := range router.Routes() {
+		if key := route.Method + " " + route.Pattern; !covered[key] {
+			t.Errorf("%s: no request in this test", key)
+		}
+	}
+
+	// Responses of the router itself use the error body too
+	for _, c := range []routeCase{
+		{method: "PUT", path: "/exchanges", status: 405},
+		{method: "GET", path: "/nowhere", status: 404},
+	} {
+		rec := serve(router, c)
+		if rec.Code != c.status {
+			t.Errorf("%s %s: status %d, want %d", c.method, c.path, rec.Code, c.status)
+		}
+		var body interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Errorf("%s %s: %v", c.method, c.path, err)
+			continue
+		}
+		schema := map[string]interface{}{"$ref": "#/components/schemas/Error"}
+		for _, problem := range validateSchema(doc, schema, body, c.method+" "+c.path) {
+			t.Error(problem)
+		}
+	}
+}
+
+func serve(handler http.Handler, c routeCase) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+	if c.body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if strings.HasPrefix(c.path, "/stream/") {
+		// The stream only ends with its client
+		ctx, cancel := context.WithTimeout(req.Context(), 50*time.Millisecond)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// checkResponse finds the response the document describes for the status of
+// rec and checks the content type and body against it
+func checkResponse(doc map[string]interface{}, c routeCase, rec *httptest.ResponseRecorder) []string {
+	operation, ok := lookup(doc, "paths", c.pattern, strings.ToLower(c.method))
+	if !ok {
+		return []string{"operation is not described"}
+	}
+	response, ok := lookup(operation, "responses", strconv.Itoa(rec.Code))
+	if !ok {
+		return []string{fmt.Sprintf("status %d is not described", rec.Code)}
+	}
+	if ref, ok := response.(map[string]interface{})["$ref"].(string); ok {
+		if response, ok = resolve(doc, ref); !ok {
+			return []string{ref + " does not resolve"}
+		}
+	}
+
+	content, ok := lookup(response, "content")
+	if !ok {
+		return nil // e.g. 101 Switching Protocols has no body
+	}
+	contentType, _, _ := strings.Cut(rec.Header().Get("Content-Type"), ";")
+	media, ok := lookup(content, contentType)
+	if !ok {
+		return []string{fmt.Sprintf("content type %q is not described", contentType)}
+	}
+	schema, ok := lookup(media, "schema")
+	if !ok || contentType != "application/json" {
+		return nil
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		return []string{fmt.Sprintf("body is not JSON: %v", err)}
+	}
+	return validateSchema(doc, schema.(map[string]interface{}), body, "body")
+}
+
+// validateSchema checks a decoded JSON value against the subset of JSON Schema
+// the document uses. Objects may only hold the properties their schema lists
+// unless it allows additional ones.
+func validateSchema(doc map[string]interface{}, schema map[string]interface{}, value interface{}, where string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, ok := resolve(doc, ref)
+		if !ok {
+			return []string{where + ": " + ref + " does not resolve"}
+		}
+		return validateSchema(doc, target.(map[string]interface{}), value, where)
+	}
+
+	if options, ok := schema["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, option := range options {
+			if len(validateSchema(doc, option.(map[string]interface{}), value, where)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return []string{fmt.Sprintf("%s: matches %d of the oneOf schemas, want 1", where, matches)}
+		}
+		return nil
+	}
+
+	var problems []string
+	if values, ok := schema["enum"].([]interface{}); ok && !slices.Contains(values, value) {
+		problems = append(problems, fmt.Sprintf("%s: %v is not one of %v", where, value, values))
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: %s, want an object", where, describe(value)))
+		}
+		for _, name := range stringList(schema["required"]) {
+			if _, ok := object[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: required property %s is missing", where, name))
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := properties[name].(map[string]interface{})
+			if !ok {
+				property, ok = schema["additionalProperties"].(map[string]interface{})
+			}
+			switch {
+			case ok:
+				problems = append(problems, validateSchema(doc, property, object[name], where+"."+name)...)
+			case properties != nil && schema["additionalProperties"] != true:
+				problems = append(problems, fmt.Sprintf("%s: property %s is not described", where, name))
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: %s, want an array", where, describe(value)))
+		}
+		if schema, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range items {
+				problems = append(problems, validateSchema(doc, schema, item, fmt.Sprintf("%s[%d]", where, i))...)
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: %s, want a string", where, describe(value)))
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, text); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %q is not a date-time", where, text))
+			}
+		}
+	case "integer":
+		if number, ok := value.(float64); !ok || number != math.Trunc(number) {
+			problems = append(problems, fmt.Sprintf("%s: %s, want an integer", where, describe(value)))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			problems = append(problems, fmt.Sprintf("%s: %s, want a number", where, describe(value)))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s: %s, want a boolean", where, describe(value)))
+		}
+	}
+	return problems
+}
+
+func describe(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+	return fmt.Sprintf("%T %v", value, value)
+}
+
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		list = append(list, item.(string))
+	}
+	return list
+}
+
+// lookup follows object keys from node
+func lookup(node interface{}, keys ...string) (interface{}, bool) {
+	for _, key := range keys {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if node, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// resolve returns the node a local reference points to
+func resolve(doc map[string]interface{}, ref string) (interface{}, bool) {
+	path, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, false
+	}
+	return lookup(doc, strings.Split(path, "/")...)
+}
+
+// newTestServer builds the server on the real use cases with in-memory ports
+// and starts data processing, which stops with the test
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	cfg := config.Defaults()
+	registry, err := symbols.NewRegistry([]models.Symbol{
+		{Name: "BTCUSDT", Base: "BTC", Quote: "USDT", Precision: 2},
+		{Name: "ETHUSDT", Base: "ETH", Quote: "USDT", Precision: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	start := now.Truncate(time.Minute).Add(-time.Minute)
+	storage := &memoryStorage{}
+	for i, exchange := range []string{"exchange1", "exchange2"} {
+		storage.rows = append(storage.rows, models.AggregatedData{
+			ID: int64(i + 1), PairName: "BTCUSDT", Exchange: exchange, Timestamp: start,
+			WindowStart: start, WindowEnd: start.Add(time.Minute), Interval: "1m",
+			AveragePrice: 100, MinPrice: 99, MaxPrice: 101, TickCount: 60,
+		})
+	}
+	storage.candles = []models.Candle{{
+		PairName: "BTCUSDT", Exchange: "exchange1", Interval: "1m", OpenTime: start, CloseTime: start.Add(time.Minute),
+		Open: 100, High: 101, Low: 99, Close: 100.5, TickCount: 60, FirstTickAt: start, LastTickAt: start.Add(59 * time.Second),
+	}}
+
+	cache := &memoryCache{latest: map[string]*models.LatestPrice{
+		"exchange1:BTCUSDT": {Symbol: "BTCUSDT", Exchange: "exchange1", Price: 100.5, Timestamp: now},
+	}}
+
+	live := newFakeSource("live", "exchange1", "exchange2")
+	test := newFakeSource("test", "test-exchange1")
+	sources := []ports.ExchangePort{live, test}
+
+	broadcaster := concurrency.NewBroadcaster(cfg.Server.Stream.ReplayBuffer, cfg.Server.Stream.ClientBuffer)
+	marketData := usecases.NewMarketDataUseCase(storage, cache, sources, registry,
+		cfg.Processing.CandleDurations(), broadcaster, logger)
+	dataProcessing := usecases.NewDataProcessingUseCase(storage, cache, concurrency.NewManager(logger), broadcaster, cfg.Processing, registry, logger)
+	dataProcessing.OnCandles(marketData.PublishCandles)
+	reload := usecases.NewReloadUseCase(func() (*config.Config, error) {
+		return config.Defaults(), nil
+	}, cfg, dataProcessing, registry, new(slog.LevelVar), logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := dataProcessing.Start(ctx, live, test); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewServer(cfg.Server, marketData, dataProcessing, reload, usecases.NewSymbolsUseCase(registry, logger), logger)
+}
+
+// memoryStorage serves seeded rows and candles and accepts every write
+type memoryStorage struct {
+	mu      sync.Mutex
+	rows    []models.AggregatedData
+	candles []models.Candle
+}
+
+func (s *memoryStorage) SaveAggregatedData(ctx context.Context, data []models.AggregatedData) error {
+	return nil
+}
+
+func (s *memoryStorage) AmendAggregatedData(ctx context.Context, data models.AggregatedData) error {
+	return nil
+}
+
+func (s *memoryStorage) SaveCorrections(ctx context.Context, corrections []models.Correction) error {
+	return nil
+}
+
+func (s *memoryStorage) GetLastWindowEnd(ctx context.Context, symbol, exchange string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (s *memoryStorage) GetAggregatedData(ctx context.Context, symbol, exchange string, from, to time.Time, after *models.AggregatedCursor, limit int) ([]models.AggregatedData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := []models.AggregatedData{}
+	for _, row := range s.rows {
+		if row.PairName != symbol || (exchange != "" && row.Exchange != exchange) ||
+			row.WindowStart.Before(from) || !row.WindowStart.Before(to) {
+			continue
+		}
+		if after != nil && (row.WindowStart.Before(after.WindowStart) ||
+			(row.WindowStart.Equal(after.WindowStart) && row.ID <= after.ID)) {
+			continue
+		}
+		if len(data) == limit {
+			break
+		}
+		data = append(data, row)
+	}
+	return data, nil
+}
+
+func (s *memoryStorage) find(symbol, exchange string) *models.AggregatedData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, row := range s.rows {
+		if row.PairName == symbol && (exchange == "" || row.Exchange == exchange) {
+			return &row
+		}
+	}
+	return nil
+}
+
+func (s *memoryStorage) GetHighestPrice(ctx context.Context, symbol, exchange string, period time.Duration) (*models.AggregatedData, error) {
+	return s.find(symbol, exchange), nil
+}
+
+func (s *memoryStorage) GetLowestPrice(ctx context.Context, symbol, exchange string, period time.Duration) (*models.AggregatedData, error) {
+	return s.find(symbol, exchange), nil
+}
+
+func (s *memoryStorage) GetAveragePrice(ctx context.Context, symbol, exchange string, period time.Duration) (*models.AggregatedData, error) {
+	return s.find(symbol, exchange), nil
+}
+
+func (s *memoryStorage) SaveCandles(ctx context.Context, candles []models.Candle) error {
+	return nil
+}
+
+func (s *memoryStorage) AmendCandles(ctx context.Context, candles []models.Candle) error {
+	return nil
+}
+
+func (s *memoryStorage) GetCandles(ctx context.Context, symbol, exchange, interval string, from, to time.Time) ([]models.Candle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candles := []models.Candle{}
+	for _, candle := range s.candles {
+		if candle.PairName == symbol && (exchange == "" || candle.Exchange == exchange) && candle.Interval == interval {
+			candles = append(candles, candle)
+		}
+	}
+	return candles, nil
+}
+
+func (s *memoryStorage) Health() models.DependencyHealth {
+	return models.DependencyHealth{Name: "postgres", State: models.DependencyUp, Critical: true}
+}
+
+func (s *memoryStorage) Close() error { return nil }
+
+// memoryCache keeps latest prices by exchange and symbol
+type memoryCache struct {
+	mu     sync.Mutex
+	latest map[string]*models.LatestPrice
+}
+
+func (c *memoryCache) SetLatestPrice(ctx context.Context, update models.PriceUpdate) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latest[update.Exchange+":"+update.Symbol] = &models.LatestPrice{
+		Symbol: update.Symbol, Exchange: update.Exchange, Price: update.Price, Timestamp: update.ReceivedAt,
+	}
+	return nil
+}
+
+func (c *memoryCache) GetLatestPrice(ctx context.Context, symbol, exchange string) (*models.LatestPrice, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latest[exchange+":"+symbol], nil
+}
+
+func (c *memoryCache) GetLatestPrices(ctx context.Context, symbol string) ([]*models.LatestPrice, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var prices []*models.LatestPrice
+	for _, price := range c.latest {
+		if price.Symbol == symbol {
+			prices = append(prices, price)
+		}
+	}
+	return prices, nil
+}
+
+func (c *memoryCache) GetPriceHistory(ctx context.Context, symbol, exchange string, from, to time.Time) ([]models.PriceUpdate, error) {
+	return nil, nil
+}
+
+func (c *memoryCache) CleanupOldData(ctx context.Context, maxAge time.Duration) error {
+	return nil
+}
+
+func (c *memoryCache) Health() models.DependencyHealth {
+	return models.DependencyHealth{Name: "redis", State: models.DependencyUp}
+}
+
+func (c *memoryCache) Close() error { return nil }
+
+// fakeSource is a connected source whose upstream feeds can be managed but
+// never deliver updates
+type fakeSource struct {
+	name string
+
+	mu        sync.Mutex
+	exchanges []models.ExchangeStatus
+	updates   chan models.PriceUpdate
+}
+
+func newFakeSource(name string, exchanges ...string) *fakeSource {
+	s := &fakeSource{name: name}
+	for _, exchange := range exchanges {
+		s.exchanges = append(s.exchanges, models.ExchangeStatus{Name: exchange, State: models.ConnectionStateConnected})
+	}
+	return s
+}
+
+func (s *fakeSource) Start(ctx context.Context) (<-chan models.PriceUpdate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates = make(chan models.PriceUpdate)
+	return s.updates, nil
+}
+
+func (s *fakeSource) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.updates != nil {
+		close(s.updates)
+		s.updates = nil
+	}
+	return nil
+}
+
+func (s *fakeSource) IsConnected() bool { return true }
+
+func (s *fakeSource) GetName() string { return s.name }
+
+func (s *fakeSource) GetExchanges() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.exchanges))
+	for _, exchange := range s.exchanges {
+		names = append(names, exchange.Name)
+	}
+	return names
+}
+
+func (s *fakeSource) GetStatus() []models.ExchangeStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.exchanges)
+}
+
+func (s *fakeSource) GetOverflowStats() models.OverflowStats {
+	return models.OverflowStats{Source: s.name, Policy: "block", Capacity: 1, Counters: []models.OverflowCounter{}}
+}
+
+func (s *fakeSource) Health() models.DependencyHealth {
+	return models.DependencyHealth{Name: "exchange", State: models.DependencyUp, Since: time.Now(), Critical: true}
+}
+
+func (s *fakeSource) index(name string) int {
+	return slices.IndexFunc(s.exchanges, func(status models.ExchangeStatus) bool { return status.Name == name })
+}
+
+func (s *fakeSource) AddExchange(cfg config.ExchangeConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index(cfg.Name) >= 0 {
+		return ports.ErrExchangeExists
+	}
+	s.exchanges = append(s.exchanges, models.ExchangeStatus{Name: cfg.Name, State: models.ConnectionStateConnecting})
+	return nil
+}
+
+func (s *fakeSource) UpdateExchange(cfg config.ExchangeConfig) error {
+	return s.setState(cfg.Name, models.ConnectionStateConnecting)
+}
+
+func (s *fakeSource) RemoveExchange(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(name)
+	if i < 0 {
+		return ports.ErrExchangeNotFound
+	}
+	s.exchanges = slices.Delete(s.exchanges, i, i+1)
+	return nil
+}
+
+func (s *fakeSource) PauseExchange(name string) error {
+	return s.setState(name, models.ConnectionStatePaused)
+}
+
+func (s *fakeSource) ResumeExchange(name string) error {
+	return s.setState(name, models.ConnectionStateConnecting)
+}
+
+func (s *fakeSource) setState(name string, state models.ConnectionState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(name)
+	if i < 0 {
+		return ports.ErrExchangeNotFound
+	}
+	s.exchanges[i].State = state
+	return nil
+}
@@ -0,0 +1,133 @@
+package web
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"marketflow/internal/adapters/web/handlers"
+)
+
+// ParamCheck validates the value of a path parameter
+type ParamCheck func(value string) error
+
+// Router dispatches requests by method and path pattern. Requests for a known
+// path with another method get a 405 listing the allowed methods, and unknown
+// paths a 404, both as JSON error bodies.
+type Router struct {
+	mux     *http.ServeMux
+	methods map[string][]string // allowed methods by path pattern
+	params  map[string]ParamCheck
+	logger  *slog.Logger
+}
+
+// NewRouter creates an empty router
+func NewRouter(logger *slog.Logger) *Router {
+	rt := &Router{
+		mux:     http.NewServeMux(),
+		methods: make(map[string][]string),
+		params:  make(map[string]ParamCheck),
+		logger:  logger,
+	}
+	rt.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		handlers.WriteError(w, http.StatusNotFound, handlers.CodeNotFound, "No route for "+r.URL.Path, nil)
+	})
+	return rt
+}
+
+// Param validates the path parameter name in every route registered afterwards
+func (rt *Router) Param(name string, check ParamCheck) {
+	rt.params[name] = check
+}
+
+// Handle registers handler for method and a path pattern with {name} parameters
+func (rt *Router) Handle(method, pattern string, handler http.HandlerFunc) {
+	if _, ok := rt.methods[pattern]; !ok {
+		rt.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			rt.methodNotAllowed(w, r, pattern)
+		})
+	}
+	rt.methods[pattern] = append(rt.methods[pattern], method)
+
+	var names []string
+	for _, name := range patternParams(pattern) {
+		if _, ok := rt.params[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	rt.mux.HandleFunc(method+" "+pattern, func(w http.ResponseWriter, r *http.Request) {
+		for _, name := range names {
+			value := r.PathValue(name)
+			if err := rt.params[name](value); err != nil {
+				handlers.WriteInvalidParameter(w, name, value, err.Error())
+				return
+			}
+		}
+		handler(w, r)
+	})
+}
+
+// Route is a method and path pattern registered with a router
+type Route struct {
+	Method  string
+	Pattern string
+}
+
+// Routes lists the registered routes by pattern
+func (rt *Router) Routes() []Route {
+	var routes []Route
+	for pattern, methods := range rt.methods {
+		for _, method := range methods {
+			routes = append(routes, Route{Method: method, Pattern: pattern})
+		}
+	}
+	slices.SortFunc(routes, func(a, b Route) int {
+		if a.Pattern != b.Pattern {
+			return strings.Compare(a.Pattern, b.Pattern)
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+	return routes
+}
+
+// ServeHTTP dispatches a request to its route
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.logger.Debug("HTTP request", "method", r.Method, "path", r.URL.Path)
+	rt.mux.ServeHTTP(w, r)
+}
+
+func (rt *Router) methodNotAllowed(w http.ResponseWriter, r *http.Request, pattern string) {
+	allowed := slices.Clone(rt.methods[pattern])
+	if slices.Contains(allowed, http.MethodGet) && !slices.Contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	handlers.WriteError(w, http.StatusMethodNotAllowed, handlers.CodeMethodNotAllowed,
+		fmt.Sprintf("Method %s not allowed for %s", r.Method, r.URL.Path),
+		map[string]interface{}{"allowed": allowed})
+}
+
+// patternParams returns the names of the {name} parameters of a pattern
+func patternParams(pattern string) []string {
+	var names []string
+	for _, segment := range strings.Split(pattern, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			names = append(names, strings.TrimSuffix(strings.Trim(segment, "{}"), "..."))
+		}
+	}
+	return names
+}
+
+// oneOf accepts only the given values
+func oneOf(values ...string) ParamCheck {
+	return func(value string) error {
+		if !slices.Contains(values, value) {
+			return fmt.Errorf("must be one of %s", strings.Join(values, ", "))
+		}
+		return nil
+	}
+}
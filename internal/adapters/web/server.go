@@ -2,89 +2,112 @@ package web
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"strings"
 	"time"
 
 	"marketflow/internal/adapters/web/handlers"
 	"marketflow/internal/application/usecases"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+	"marketflow/internal/domain/symbols"
 )
 
 // Server represents the HTTP server
 type Server struct {
-	port                  int
+	cfg                   config.ServerConfig
 	marketDataUseCase     *usecases.MarketDataUseCase
 	dataProcessingUseCase *usecases.DataProcessingUseCase
+	reloadUseCase         *usecases.ReloadUseCase
+	symbolsUseCase        *usecases.SymbolsUseCase
 	logger                *slog.Logger
 	server                *http.Server
 }
 
 // NewServer creates a new HTTP server
-func NewServer(port int, marketDataUseCase *usecases.MarketDataUseCase, dataProcessingUseCase *usecases.DataProcessingUseCase, logger *slog.Logger) *Server {
+func NewServer(cfg config.ServerConfig, marketDataUseCase *usecases.MarketDataUseCase, dataProcessingUseCase *usecases.DataProcessingUseCase, reloadUseCase *usecases.ReloadUseCase, symbolsUseCase *usecases.SymbolsUseCase, logger *slog.Logger) *Server {
 	return &Server{
-		port:                  port,
+		cfg:                   cfg,
 		marketDataUseCase:     marketDataUseCase,
 		dataProcessingUseCase: dataProcessingUseCase,
+		reloadUseCase:         reloadUseCase,
+		symbolsUseCase:        symbolsUseCase,
 		logger:                logger,
 	}
 }
 
 // Start starts the HTTP server
 func (s *Server) Start() error {
-	mux := http.NewServeMux()
+	s.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", s.cfg.Port),
+		Handler: s.routes(),
+	}
+
+	s.logger.Info("Starting HTTP server", "port", s.cfg.Port)
+	return s.server.ListenAndServe()
+}
 
+// routes builds the router of every endpoint
+func (s *Server) routes() *Router {
 	// Initialize handlers
 	pricesHandler := handlers.NewPricesHandler(s.marketDataUseCase, s.logger)
+	candlesHandler := handlers.NewCandlesHandler(s.marketDataUseCase, s.logger)
+	historyHandler := handlers.NewHistoryHandler(s.marketDataUseCase, s.logger)
+	streamHandler := handlers.NewStreamHandler(s.marketDataUseCase, s.cfg.Stream.Heartbeat.Std(), s.logger)
+	webSocketHandler := handlers.NewWebSocketHandler(s.marketDataUseCase, s.cfg.WebSocket, s.logger)
 	modeHandler := handlers.NewModeHandler(s.dataProcessingUseCase, s.logger)
-	healthHandler := handlers.NewHealthHandler(s.logger)
+	healthHandler := handlers.NewHealthHandler(s.dataProcessingUseCase, s.cfg.Health.Critical, s.logger)
 	statusHandler := handlers.NewStatusHandler(s.dataProcessingUseCase, s.logger)
+	backpressureHandler := handlers.NewBackpressureHandler(s.dataProcessingUseCase, s.logger)
+	exchangesHandler := handlers.NewExchangesHandler(s.dataProcessingUseCase, s.logger)
+	adminHandler := handlers.NewAdminHandler(s.reloadUseCase, s.logger)
+	symbolsHandler := handlers.NewSymbolsHandler(s.symbolsUseCase, s.logger)
 
-	// Register routes
-	mux.HandleFunc("/prices/", func(w http.ResponseWriter, r *http.Request) {
-		s.logger.Debug("Prices request", "method", r.Method, "path", r.URL.Path)
-		pricesHandler.Handle(w, r)
-	})
+	router := NewRouter(s.logger)
 
-	mux.HandleFunc("/mode/", func(w http.ResponseWriter, r *http.Request) {
-		s.logger.Debug("Mode request", "method", r.Method, "path", r.URL.Path)
-		modeHandler.Handle(w, r)
-	})
-
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		s.logger.Debug("Health request", "method", r.Method, "path", r.URL.Path)
-		healthHandler.Handle(w, r)
-	})
-
-	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
-		s.logger.Debug("Status request", "method", r.Method, "path", r.URL.Path)
-		statusHandler.Handle(w, r)
-	})
-
-	// Add a catch-all for debugging
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		s.logger.Info("Unmatched request", "method", r.Method, "path", r.URL.Path)
-		if strings.HasPrefix(r.URL.Path, "/prices/") {
-			pricesHandler.Handle(w, r)
-		} else if strings.HasPrefix(r.URL.Path, "/mode/") {
-			modeHandler.Handle(w, r)
-		} else if r.URL.Path == "/health" {
-			healthHandler.Handle(w, r)
-		} else if r.URL.Path == "/status" {
-			statusHandler.Handle(w, r)
-		} else {
-			http.NotFound(w, r)
+	// Typed path parameters
+	router.Param("symbol", func(value string) error {
+		if !symbols.ValidName(value) {
+			return errors.New("must be 2-20 upper-case letters or digits")
 		}
+		return nil
 	})
+	router.Param("operation", oneOf("latest", "highest", "lowest", "average"))
+	router.Param("mode", oneOf(string(models.DataModeLive), string(models.DataModeTest)))
 
-	s.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.port),
-		Handler: mux,
-	}
-
-	s.logger.Info("Starting HTTP server", "port", s.port)
-	return s.server.ListenAndServe()
+	// Register routes
+	router.Handle(http.MethodGet, "/prices/{operation}/{symbol}", pricesHandler.Handle)
+	router.Handle(http.MethodGet, "/prices/{operation}/{exchange}/{symbol}", pricesHandler.Handle)
+	router.Handle(http.MethodGet, "/candles/{symbol}", candlesHandler.Handle)
+	router.Handle(http.MethodGet, "/history/{symbol}", historyHandler.Handle)
+	router.Handle(http.MethodGet, "/stream/prices", streamHandler.Handle)
+	router.Handle(http.MethodGet, "/ws", webSocketHandler.Handle)
+
+	router.Handle(http.MethodPost, "/mode/{mode}", modeHandler.Handle)
+
+	router.Handle(http.MethodGet, "/health", healthHandler.Handle)
+	router.Handle(http.MethodGet, "/health/live", healthHandler.HandleLive)
+	router.Handle(http.MethodGet, "/health/ready", healthHandler.HandleReady)
+	router.Handle(http.MethodGet, "/status", statusHandler.Handle)
+	router.Handle(http.MethodGet, "/backpressure", backpressureHandler.Handle)
+
+	router.Handle(http.MethodGet, "/exchanges", exchangesHandler.List)
+	router.Handle(http.MethodPost, "/exchanges", exchangesHandler.Add)
+	router.Handle(http.MethodDelete, "/exchanges/{name}", exchangesHandler.Remove)
+	router.Handle(http.MethodPost, "/exchanges/{name}/pause", exchangesHandler.Pause)
+	router.Handle(http.MethodPost, "/exchanges/{name}/resume", exchangesHandler.Resume)
+
+	router.Handle(http.MethodPost, "/admin/reload", adminHandler.Reload)
+	router.Handle(http.MethodGet, "/admin/symbols", symbolsHandler.List)
+	router.Handle(http.MethodPost, "/admin/symbols", symbolsHandler.Add)
+	router.Handle(http.MethodGet, "/admin/symbols/{symbol}", symbolsHandler.Get)
+	router.Handle(http.MethodDelete, "/admin/symbols/{symbol}", symbolsHandler.Remove)
+
+	router.Handle(http.MethodGet, "/openapi.json", serveOpenAPI)
+
+	return router
 }
 
 // Shutdown gracefully shuts down the server
@@ -18,12 +18,15 @@ type CachePort interface {
 	// GetLatestPrices gets latest prices for a symbol from all exchanges
 	GetLatestPrices(ctx context.Context, symbol string) ([]*models.LatestPrice, error)
 
-	// GetPriceHistory gets price history for aggregation (last minute)
-	GetPriceHistory(ctx context.Context, symbol, exchange string, duration time.Duration) ([]models.PriceUpdate, error)
+	// GetPriceHistory gets the updates received within [from, to) for aggregation
+	GetPriceHistory(ctx context.Context, symbol, exchange string, from, to time.Time) ([]models.PriceUpdate, error)
 
 	// CleanupOldData removes old price data from cache
 	CleanupOldData(ctx context.Context, maxAge time.Duration) error
 
+	// Health returns the state of the connection to the cache
+	Health() models.DependencyHealth
+
 	// Close closes the cache connection
 	Close() error
 }
@@ -2,10 +2,20 @@ package ports
 
 import (
 	"context"
+	"errors"
 
+	"marketflow/internal/config"
 	"marketflow/internal/domain/models"
 )
 
+var (
+	// ErrExchangeNotFound is returned when an upstream exchange is not registered
+	ErrExchangeNotFound = errors.New("exchange not found")
+
+	// ErrExchangeExists is returned when adding an upstream whose name is taken
+	ErrExchangeExists = errors.New("exchange already exists")
+)
+
 // ExchangePort defines the interface for exchange data sources
 type ExchangePort interface {
 	// Start begins data collection
@@ -19,4 +29,40 @@ type ExchangePort interface {
 
 	// GetName returns the exchange name
 	GetName() string
+
+	// GetExchanges returns the names of the upstream exchanges this source publishes
+	GetExchanges() []string
+
+	// GetStatus returns the connection status of every upstream feed
+	GetStatus() []models.ExchangeStatus
+
+	// GetOverflowStats returns counters of updates dropped or coalesced under backpressure
+	GetOverflowStats() models.OverflowStats
+
+	// Health reports whether the source is delivering updates
+	Health() models.DependencyHealth
+}
+
+// FeedNotifier is implemented by sources whose upstream feeds change state on their own
+type FeedNotifier interface {
+	// FeedChanges returns a channel that receives a value after an upstream feed changes state
+	FeedChanges() <-chan struct{}
+}
+
+// ExchangeManager is implemented by sources whose upstream feeds can be changed at runtime
+type ExchangeManager interface {
+	// AddExchange registers and connects a new upstream
+	AddExchange(cfg config.ExchangeConfig) error
+
+	// RemoveExchange disconnects and forgets an upstream
+	RemoveExchange(name string) error
+
+	// PauseExchange disconnects an upstream but keeps it registered
+	PauseExchange(name string) error
+
+	// ResumeExchange reconnects a paused upstream
+	ResumeExchange(name string) error
+
+	// UpdateExchange replaces the configuration of an upstream, matched by name, and reconnects it
+	UpdateExchange(cfg config.ExchangeConfig) error
 }
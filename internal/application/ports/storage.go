@@ -9,11 +9,21 @@ import (
 
 // StoragePort defines the interface for data storage operations
 type StoragePort interface {
-	// SaveAggregatedData saves aggregated market data
+	// SaveAggregatedData saves aggregated market data; saving a window again replaces it
 	SaveAggregatedData(ctx context.Context, data []models.AggregatedData) error
 
-	// GetAggregatedData retrieves aggregated data within a time range
-	GetAggregatedData(ctx context.Context, symbol, exchange string, from, to time.Time) ([]models.AggregatedData, error)
+	// AmendAggregatedData folds a partial aggregate into the persisted row of the same window, creating the row if missing
+	AmendAggregatedData(ctx context.Context, data models.AggregatedData) error
+
+	// SaveCorrections records updates that arrived after their window was aggregated
+	SaveCorrections(ctx context.Context, corrections []models.Correction) error
+
+	// GetLastWindowEnd returns the end of the latest persisted window of a symbol on an exchange, or the zero time if none
+	GetLastWindowEnd(ctx context.Context, symbol, exchange string) (time.Time, error)
+
+	// GetAggregatedData retrieves up to limit windows starting within [from, to),
+	// ordered by window start and ID, after the given row when after is set
+	GetAggregatedData(ctx context.Context, symbol, exchange string, from, to time.Time, after *models.AggregatedCursor, limit int) ([]models.AggregatedData, error)
 
 	// GetHighestPrice returns the highest price within a period
 	GetHighestPrice(ctx context.Context, symbol, exchange string, period time.Duration) (*models.AggregatedData, error)
@@ -24,6 +34,24 @@ type StoragePort interface {
 	// GetAveragePrice returns the average price within a period
 	GetAveragePrice(ctx context.Context, symbol, exchange string, period time.Duration) (*models.AggregatedData, error)
 
+	// SaveCandles saves completed OHLC candles; saving a candle again replaces it
+	SaveCandles(ctx context.Context, candles []models.Candle) error
+
+	// AmendCandles folds partial candles into the persisted candles of the same window, creating them if missing
+	AmendCandles(ctx context.Context, candles []models.Candle) error
+
+	// GetCandles retrieves candles of one interval opened within [from, to), oldest first; an empty exchange matches all
+	GetCandles(ctx context.Context, symbol, exchange, interval string, from, to time.Time) ([]models.Candle, error)
+
+	// Health returns the state of the connection to the database
+	Health() models.DependencyHealth
+
 	// Close closes the storage connection
 	Close() error
 }
+
+// SpoolReporter is implemented by storage that keeps rejected batches on disk until they can be replayed
+type SpoolReporter interface {
+	// GetSpoolStatus returns the size and age of the batches waiting to be replayed
+	GetSpoolStatus() models.SpoolStatus
+}
@@ -8,50 +8,108 @@ import (
 
 	"marketflow/internal/application/ports"
 	"marketflow/internal/concurrency"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/aggregation"
 	"marketflow/internal/domain/models"
+	"marketflow/internal/domain/symbols"
+)
+
+const (
+	defaultBatchSize           = 100
+	defaultAggregationInterval = time.Minute
+	candleFlushInterval        = time.Second
 )
 
 // DataProcessingUseCase handles data processing operations
 type DataProcessingUseCase struct {
-	storage             ports.StoragePort
-	cache               ports.CachePort
-	concurrencyManager  *concurrency.Manager
-	logger              *slog.Logger
-	mode                models.DataMode
-	mu                  sync.RWMutex
-	ctx                 context.Context
-	cancel              context.CancelFunc
-	liveExchange        ports.ExchangePort
-	testExchange        ports.ExchangePort
-	isRunning           bool
+	storage            ports.StoragePort
+	cache              ports.CachePort
+	concurrencyManager *concurrency.Manager
+	broadcaster        *concurrency.Broadcaster
+	logger             *slog.Logger
+	mode               models.DataMode
+	mu                 sync.RWMutex
+	baseCtx            context.Context
+	ctx                context.Context
+	cancel             context.CancelFunc
+	liveExchange       ports.ExchangePort
+	testExchange       ports.ExchangePort
+	isRunning          bool
+	workersPerExchange int
+	symbols            *symbols.Registry
+	batchSize          int
+	interval           time.Duration
+	historyRetention   time.Duration // how long the cache keeps price history
+	candles            *aggregation.CandleBuilder
+	onCandles          func([]models.Candle) // called with every batch of completed candles
+	windowing          *windowingState
+	windows            *aggregation.WindowAggregator
+	failover           failoverState
 }
 
 // NewDataProcessingUseCase creates a new DataProcessingUseCase
-func NewDataProcessingUseCase(storage ports.StoragePort, cache ports.CachePort, concurrencyManager *concurrency.Manager, logger *slog.Logger) *DataProcessingUseCase {
+func NewDataProcessingUseCase(storage ports.StoragePort, cache ports.CachePort, concurrencyManager *concurrency.Manager, broadcaster *concurrency.Broadcaster, cfg config.ProcessingConfig, registry *symbols.Registry, logger *slog.Logger) *DataProcessingUseCase {
+	workers := cfg.WorkersPerExchange
+	if workers < 1 {
+		workers = defaultWorkersPerExchange
+	}
+	batchSize := cfg.BatchSize
+	if batchSize < 1 {
+		batchSize = defaultBatchSize
+	}
+	interval := cfg.AggregationInterval.Std()
+	if interval <= 0 {
+		interval = defaultAggregationInterval
+	}
+
 	return &DataProcessingUseCase{
 		storage:            storage,
 		cache:              cache,
 		concurrencyManager: concurrencyManager,
+		broadcaster:        broadcaster,
 		logger:             logger,
 		mode:               models.DataModeLive,
 		isRunning:          false,
+		workersPerExchange: workers,
+		symbols:            registry,
+		batchSize:          batchSize,
+		interval:           interval,
+		historyRetention:   cfg.HistoryRetention(),
+		candles:            aggregation.NewCandleBuilder(cfg.CandleDurations(), cfg.Windowing.AllowedLateness.Std()),
+		windowing:          newWindowingState(cfg.Windowing),
+		windows:            aggregation.NewWindowAggregator(interval),
+		failover:           newFailoverState(cfg.Failover),
 	}
 }
 
 // Start begins data processing
 func (uc *DataProcessingUseCase) Start(ctx context.Context, liveExchange, testExchange ports.ExchangePort) error {
+	// Rebuild windows left incomplete by a previous run before any new update arrives
+	current := uc.recoverWindows(ctx, exchangeNames([]ports.ExchangePort{liveExchange, testExchange}))
+
 	uc.mu.Lock()
 	defer uc.mu.Unlock()
 
 	uc.liveExchange = liveExchange
 	uc.testExchange = testExchange
+	uc.baseCtx = ctx
 	uc.ctx, uc.cancel = context.WithCancel(ctx)
 
 	// Start aggregation ticker
-	go uc.startAggregationTicker(uc.ctx)
+	go uc.startAggregationTicker(ctx, current.Add(uc.interval))
 
 	// Start cleanup ticker
-	go uc.startCleanupTicker(uc.ctx)
+	go uc.startCleanupTicker(ctx)
+
+	// Start candle flusher
+	if len(uc.candles.Intervals()) > 0 {
+		go uc.startCandleFlusher(ctx)
+	}
+
+	// Start failover monitor
+	if uc.failover.cfg.Enabled {
+		go uc.startFailoverMonitor(ctx)
+	}
 
 	// Start data processing based on current mode
 	go uc.startDataProcessing(uc.ctx)
@@ -74,9 +132,12 @@ func (uc *DataProcessingUseCase) SetMode(mode models.DataMode) {
 		// Restart data processing with new mode if system is running
 		if uc.isRunning && uc.cancel != nil {
 			uc.cancel() // Stop current processing
+			uc.stopExchange(uc.exchangeFor(oldMode))
+			uc.resetFailover()
+			uc.recordTransition(oldMode, mode, "mode switched manually", time.Now())
 
 			// Start new processing with updated mode
-			uc.ctx, uc.cancel = context.WithCancel(context.Background())
+			uc.ctx, uc.cancel = context.WithCancel(uc.baseCtx)
 			go uc.startDataProcessing(uc.ctx)
 
 			uc.logger.Info("Data processing restarted with new mode", "mode", mode)
@@ -84,6 +145,17 @@ func (uc *DataProcessingUseCase) SetMode(mode models.DataMode) {
 	}
 }
 
+// SetBatchSize changes how many aggregated rows are written per insert
+func (uc *DataProcessingUseCase) SetBatchSize(size int) {
+	if size < 1 {
+		size = defaultBatchSize
+	}
+
+	uc.mu.Lock()
+	uc.batchSize = size
+	uc.mu.Unlock()
+}
+
 // GetMode returns the current data mode
 func (uc *DataProcessingUseCase) GetMode() models.DataMode {
 	uc.mu.RLock()
@@ -91,17 +163,106 @@ func (uc *DataProcessingUseCase) GetMode() models.DataMode {
 	return uc.mode
 }
 
+// GetExchangeStatuses returns the upstream statuses of the active exchange
+func (uc *DataProcessingUseCase) GetExchangeStatuses() []models.ExchangeStatus {
+	exchange := uc.activeExchange()
+	if exchange == nil {
+		return []models.ExchangeStatus{}
+	}
+	return exchange.GetStatus()
+}
+
+// IsExchangeConnected reports whether the active exchange has a live upstream
+func (uc *DataProcessingUseCase) IsExchangeConnected() bool {
+	exchange := uc.activeExchange()
+	return exchange != nil && exchange.IsConnected()
+}
+
+// GetExchanges returns the names of the exchanges published by the live and test sources
+func (uc *DataProcessingUseCase) GetExchanges() []string {
+	uc.mu.RLock()
+	sources := []ports.ExchangePort{uc.liveExchange, uc.testExchange}
+	uc.mu.RUnlock()
+
+	return exchangeNames(sources)
+}
+
+// GetOverflowStats returns backpressure counters for every data source
+func (uc *DataProcessingUseCase) GetOverflowStats() []models.OverflowStats {
+	uc.mu.RLock()
+	exchanges := []ports.ExchangePort{uc.liveExchange, uc.testExchange}
+	uc.mu.RUnlock()
+
+	stats := make([]models.OverflowStats, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		if exchange != nil {
+			stats = append(stats, exchange.GetOverflowStats())
+		}
+	}
+	return stats
+}
+
+// GetSpoolStatus returns the batches waiting on disk for the database, if a spool is configured
+func (uc *DataProcessingUseCase) GetSpoolStatus() models.SpoolStatus {
+	if reporter, ok := uc.storage.(ports.SpoolReporter); ok {
+		return reporter.GetSpoolStatus()
+	}
+	return models.SpoolStatus{}
+}
+
+// GetStreamStats returns the subscriber and event counters of the live price stream
+func (uc *DataProcessingUseCase) GetStreamStats() models.StreamStats {
+	return uc.broadcaster.Stats()
+}
+
+// GetDependencyHealth returns the state of the database and cache connections
+// and of the source updates currently come from
+func (uc *DataProcessingUseCase) GetDependencyHealth() []models.DependencyHealth {
+	uc.mu.RLock()
+	exchange := uc.exchangeFor(uc.mode)
+	if uc.failover.active {
+		exchange = uc.testExchange
+	}
+	uc.mu.RUnlock()
+
+	dependencies := []models.DependencyHealth{uc.storage.Health(), uc.cache.Health()}
+	if exchange != nil {
+		dependencies = append(dependencies, exchange.Health())
+	}
+	return dependencies
+}
+
+func (uc *DataProcessingUseCase) activeExchange() ports.ExchangePort {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	return uc.exchangeFor(uc.mode)
+}
+
+// exchangeFor returns the exchange serving a mode; callers must hold uc.mu
+func (uc *DataProcessingUseCase) exchangeFor(mode models.DataMode) ports.ExchangePort {
+	if mode == models.DataModeLive {
+		return uc.liveExchange
+	}
+	return uc.testExchange
+}
+
+// stopExchange stops an exchange and releases its worker pool
+func (uc *DataProcessingUseCase) stopExchange(exchange ports.ExchangePort) {
+	if exchange == nil {
+		return
+	}
+	if err := exchange.Stop(); err != nil {
+		uc.logger.Error("Failed to stop exchange", "error", err, "exchange", exchange.GetName())
+	}
+	uc.concurrencyManager.StopWorkerPool(exchange.GetName())
+}
+
 func (uc *DataProcessingUseCase) startDataProcessing(ctx context.Context) {
 	uc.logger.Info("Starting data processing pipeline")
 
 	// Choose exchange based on current mode
 	uc.mu.RLock()
-	var exchange ports.ExchangePort
-	if uc.mode == models.DataModeLive {
-		exchange = uc.liveExchange
-	} else {
-		exchange = uc.testExchange
-	}
+	exchange := uc.exchangeFor(uc.mode)
 	currentMode := uc.mode
 	uc.mu.RUnlock()
 
@@ -110,26 +271,40 @@ func (uc *DataProcessingUseCase) startDataProcessing(ctx context.Context) {
 		return
 	}
 
-	uc.logger.Info("Starting exchange data stream", "mode", currentMode, "exchange", exchange.GetName())
+	uc.startPipeline(ctx, exchange)
+}
+
+// startPipeline streams an exchange through a worker pool into the result processor
+func (uc *DataProcessingUseCase) startPipeline(ctx context.Context, exchange ports.ExchangePort) {
+	uc.logger.Info("Starting exchange data stream", "exchange", exchange.GetName())
 
 	// Start exchange data stream
 	dataCh, err := exchange.Start(ctx)
 	if err != nil {
-		uc.logger.Error("Failed to start exchange", "error", err, "mode", currentMode)
+		uc.logger.Error("Failed to start exchange", "error", err, "exchange", exchange.GetName())
 		return
 	}
 
 	// Create channels for concurrency patterns
 	processedCh := make(chan models.PriceUpdate, 1000)
+	resultCh := make(chan models.PriceUpdate, 1000)
+	streamCh := make(chan models.PriceUpdate, 1000)
 
-	// Start worker pools (5 workers as specified in requirements)
-	numWorkers := 5
+	// Start worker pools sized by the number of active upstream feeds
+	numWorkers := uc.workersFor(exchange)
 	uc.concurrencyManager.StartWorkerPool(ctx, exchange.GetName(), numWorkers, dataCh, processedCh)
+	if notifier, ok := exchange.(ports.FeedNotifier); ok {
+		go uc.watchFeeds(ctx, exchange, notifier.FeedChanges())
+	}
+
+	// Hand every processed update to the result processor and to stream subscribers
+	uc.concurrencyManager.FanOut(ctx, processedCh, []chan<- models.PriceUpdate{resultCh, streamCh})
+	go uc.broadcaster.Run(ctx, streamCh)
 
 	// Start result processor
-	go uc.processResults(ctx, processedCh)
+	go uc.processResults(ctx, resultCh)
 
-	uc.logger.Info("Data processing pipeline started", "mode", currentMode, "exchange", exchange.GetName())
+	uc.logger.Info("Data processing pipeline started", "exchange", exchange.GetName())
 }
 
 func (uc *DataProcessingUseCase) processResults(ctx context.Context, resultCh <-chan models.PriceUpdate) {
@@ -155,12 +330,24 @@ func (uc *DataProcessingUseCase) processResults(ctx context.Context, resultCh <-
 }
 
 func (uc *DataProcessingUseCase) processPriceUpdate(ctx context.Context, update models.PriceUpdate) error {
-	// Cache the latest price in Redis
+	// Cache the latest price and its history in Redis, late or not, so the
+	// latest price never lags behind a slow feed
 	if err := uc.cache.SetLatestPrice(ctx, update); err != nil {
 		uc.logger.Error("Failed to cache price update", "error", err)
 		// Don't return error - continue processing even if cache fails
 	}
 
+	// Place the update in its aggregation window and its candles by the
+	// configured time source; the parts that already closed go to the late policy
+	at := uc.windowing.timeOf(update)
+	lateCandles := uc.candles.Add(update, at)
+	if late := uc.addToWindow(update, at); late {
+		uc.handleLateUpdate(ctx, update, at)
+	}
+	if len(lateCandles) > 0 {
+		uc.handleLateCandles(ctx, update, lateCandles)
+	}
+
 	uc.logger.Debug("Processed price update",
 		"symbol", update.Symbol,
 		"exchange", update.Exchange,
@@ -169,19 +356,85 @@ func (uc *DataProcessingUseCase) processPriceUpdate(ctx context.Context, update
 	return nil
 }
 
-func (uc *DataProcessingUseCase) startAggregationTicker(ctx context.Context) {
-	ticker := time.NewTicker(time.Minute)
-	defer ticker.Stop()
+// startAggregationTicker aggregates each wall-clock aligned window, starting
+// with the one ending at next, once it has closed, waiting the allowed
+// lateness past the boundary for delayed updates
+func (uc *DataProcessingUseCase) startAggregationTicker(ctx context.Context, next time.Time) {
+	lateness := uc.windowing.lateness
+	timer := time.NewTimer(time.Until(next.Add(lateness)))
+	defer timer.Stop()
 
-	uc.logger.Info("Starting aggregation ticker")
+	uc.logger.Info("Starting aggregation ticker", "interval", uc.interval,
+		"time_source", uc.windowing.source, "allowed_lateness", lateness, "first_window_end", next)
 
 	for {
 		select {
 		case <-ctx.Done():
 			uc.logger.Info("Aggregation ticker stopped")
 			return
-		case <-ticker.C:
-			uc.aggregateData(ctx)
+		case now := <-timer.C:
+			// Catch up on every window that closed, e.g. after the process was suspended
+			for !next.Add(lateness).After(now) {
+				uc.aggregateWindow(ctx, next.Add(-uc.interval), next)
+				next = next.Add(uc.interval)
+			}
+			timer.Reset(time.Until(next.Add(lateness)))
+		}
+	}
+}
+
+func (uc *DataProcessingUseCase) startCandleFlusher(ctx context.Context) {
+	ticker := time.NewTicker(candleFlushInterval)
+	defer ticker.Stop()
+
+	uc.logger.Info("Starting candle flusher", "intervals", len(uc.candles.Intervals()))
+
+	for {
+		select {
+		case <-ctx.Done():
+			uc.logger.Info("Candle flusher stopped")
+			return
+		case now := <-ticker.C:
+			candles := uc.candles.Flush(now)
+			if len(candles) == 0 {
+				continue
+			}
+
+			uc.mu.RLock()
+			onCandles := uc.onCandles
+			uc.mu.RUnlock()
+			if onCandles != nil {
+				onCandles(candles)
+			}
+			uc.saveCandles(ctx, candles)
+		}
+	}
+}
+
+// OnCandles sets the function called with every batch of completed candles,
+// the same ones that are stored
+func (uc *DataProcessingUseCase) OnCandles(fn func([]models.Candle)) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.onCandles = fn
+}
+
+// saveCandles stores completed candles, batch_size rows at a time
+func (uc *DataProcessingUseCase) saveCandles(ctx context.Context, candles []models.Candle) {
+	uc.mu.RLock()
+	batchSize := uc.batchSize
+	uc.mu.RUnlock()
+
+	for start := 0; start < len(candles); start += batchSize {
+		end := start + batchSize
+		if end > len(candles) {
+			end = len(candles)
+		}
+
+		if err := uc.storage.SaveCandles(ctx, candles[start:end]); err != nil {
+			uc.logger.Error("Failed to save candles", "error", err, "count", end-start)
+		} else {
+			uc.logger.Debug("Saved candles", "count", end-start)
 		}
 	}
 }
@@ -198,65 +451,45 @@ func (uc *DataProcessingUseCase) startCleanupTicker(ctx context.Context) {
 			uc.logger.Info("Cleanup ticker stopped")
 			return
 		case <-ticker.C:
-			if err := uc.cache.CleanupOldData(ctx, 2*time.Minute); err != nil {
+			if err := uc.cache.CleanupOldData(ctx, uc.historyRetention); err != nil {
 				uc.logger.Error("Failed to cleanup old data", "error", err)
 			}
 		}
 	}
 }
 
-func (uc *DataProcessingUseCase) aggregateData(ctx context.Context) {
-	uc.logger.Info("Starting data aggregation")
-
-	symbols := []string{"BTCUSDT", "DOGEUSDT", "TONUSDT", "SOLUSDT", "ETHUSDT"}
-	exchanges := []string{"exchange1", "exchange2", "exchange3", "test-exchange1", "test-exchange2", "test-exchange3"}
+// aggregateWindow closes the windows ending at or before end and stores their
+// running statistics; windows never overlap, so every update is counted in
+// exactly one row per exchange or, arriving after its window closed, handed to the late policy
+func (uc *DataProcessingUseCase) aggregateWindow(ctx context.Context, start, end time.Time) {
+	uc.logger.Info("Starting data aggregation", "window_start", start, "window_end", end)
 
-	var aggregatedData []models.AggregatedData
-
-	for _, symbol := range symbols {
-		for _, exchange := range exchanges {
-			// Get price history for the last minute
-			history, err := uc.cache.GetPriceHistory(ctx, symbol, exchange, time.Minute)
-			if err != nil || len(history) == 0 {
-				continue
-			}
-
-			// Calculate aggregations
-			var total, min, max float64
-			min = history[0].Price
-			max = history[0].Price
-
-			for _, price := range history {
-				total += price.Price
-				if price.Price < min {
-					min = price.Price
-				}
-				if price.Price > max {
-					max = price.Price
-				}
-			}
-
-			avg := total / float64(len(history))
+	uc.saveAggregated(ctx, uc.windows.Close(end))
+}
 
-			aggregated := models.AggregatedData{
-				PairName:     symbol,
-				Exchange:     exchange,
-				Timestamp:    time.Now(),
-				AveragePrice: avg,
-				MinPrice:     min,
-				MaxPrice:     max,
-			}
+// saveAggregated stores the closed windows of tracked symbols, batch_size rows at a time
+func (uc *DataProcessingUseCase) saveAggregated(ctx context.Context, windows []models.AggregatedData) {
+	uc.mu.RLock()
+	batchSize := uc.batchSize
+	uc.mu.RUnlock()
 
-			aggregatedData = append(aggregatedData, aggregated)
+	var aggregatedData []models.AggregatedData
+	for _, window := range windows {
+		if _, ok := uc.symbols.Get(window.PairName); ok {
+			aggregatedData = append(aggregatedData, window)
 		}
 	}
 
-	// Store aggregated data in PostgreSQL
-	if len(aggregatedData) > 0 {
-		if err := uc.storage.SaveAggregatedData(ctx, aggregatedData); err != nil {
-			uc.logger.Error("Failed to save aggregated data", "error", err)
+	for start := 0; start < len(aggregatedData); start += batchSize {
+		end := start + batchSize
+		if end > len(aggregatedData) {
+			end = len(aggregatedData)
+		}
+
+		if err := uc.storage.SaveAggregatedData(ctx, aggregatedData[start:end]); err != nil {
+			uc.logger.Error("Failed to save aggregated data", "error", err, "count", end-start)
 		} else {
-			uc.logger.Info("Saved aggregated data", "count", len(aggregatedData))
+			uc.logger.Info("Saved aggregated data", "count", end-start)
 		}
 	}
 }
@@ -0,0 +1,176 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"marketflow/internal/application/ports"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+)
+
+// defaultWorkersPerExchange is the worker pool share of every active upstream feed
+const defaultWorkersPerExchange = 5
+
+var (
+	// ErrInvalidExchange is returned when an exchange definition is incomplete
+	ErrInvalidExchange = errors.New("invalid exchange")
+
+	// ErrExchangeManagementUnsupported is returned when the live source cannot be changed at runtime
+	ErrExchangeManagementUnsupported = errors.New("live source does not support runtime exchange management")
+)
+
+// ListExchanges returns the status of every live upstream feed
+func (uc *DataProcessingUseCase) ListExchanges() []models.ExchangeStatus {
+	uc.mu.RLock()
+	exchange := uc.liveExchange
+	uc.mu.RUnlock()
+
+	if exchange == nil {
+		return []models.ExchangeStatus{}
+	}
+	return exchange.GetStatus()
+}
+
+// AddExchange attaches a new live upstream feed
+func (uc *DataProcessingUseCase) AddExchange(cfg config.ExchangeConfig) error {
+	if err := validateExchange(cfg); err != nil {
+		return err
+	}
+
+	return uc.manageExchange(func(manager ports.ExchangeManager) error {
+		return manager.AddExchange(cfg)
+	})
+}
+
+// UpdateExchange reconnects a live upstream feed with a new definition
+func (uc *DataProcessingUseCase) UpdateExchange(cfg config.ExchangeConfig) error {
+	if err := validateExchange(cfg); err != nil {
+		return err
+	}
+
+	return uc.manageExchange(func(manager ports.ExchangeManager) error {
+		return manager.UpdateExchange(cfg)
+	})
+}
+
+// RemoveExchange detaches a live upstream feed
+func (uc *DataProcessingUseCase) RemoveExchange(name string) error {
+	return uc.manageExchange(func(manager ports.ExchangeManager) error {
+		return manager.RemoveExchange(name)
+	})
+}
+
+// PauseExchange disconnects a live upstream feed without removing it
+func (uc *DataProcessingUseCase) PauseExchange(name string) error {
+	return uc.manageExchange(func(manager ports.ExchangeManager) error {
+		return manager.PauseExchange(name)
+	})
+}
+
+// ResumeExchange reconnects a paused live upstream feed
+func (uc *DataProcessingUseCase) ResumeExchange(name string) error {
+	return uc.manageExchange(func(manager ports.ExchangeManager) error {
+		return manager.ResumeExchange(name)
+	})
+}
+
+// GetWorkerPoolSizes returns the number of workers serving each source
+func (uc *DataProcessingUseCase) GetWorkerPoolSizes() map[string]int {
+	return uc.concurrencyManager.WorkerPoolSizes()
+}
+
+// manageExchange applies a change to the live source and resizes its worker pool to match
+func (uc *DataProcessingUseCase) manageExchange(change func(manager ports.ExchangeManager) error) error {
+	uc.mu.RLock()
+	exchange := uc.liveExchange
+	uc.mu.RUnlock()
+
+	manager, ok := exchange.(ports.ExchangeManager)
+	if !ok {
+		return ErrExchangeManagementUnsupported
+	}
+
+	if err := change(manager); err != nil {
+		return err
+	}
+
+	uc.resizeWorkerPool(exchange)
+	return nil
+}
+
+func (uc *DataProcessingUseCase) resizeWorkerPool(exchange ports.ExchangePort) {
+	workers := uc.workersFor(exchange)
+	if uc.concurrencyManager.ResizeWorkerPool(exchange.GetName(), workers) {
+		uc.logger.Info("Worker pool resized to match active feeds", "exchange", exchange.GetName(), "workers", workers)
+	}
+}
+
+// SetWorkersPerExchange changes the worker share of every active feed and resizes running pools
+func (uc *DataProcessingUseCase) SetWorkersPerExchange(workers int) {
+	if workers < 1 {
+		workers = defaultWorkersPerExchange
+	}
+
+	uc.mu.Lock()
+	uc.workersPerExchange = workers
+	exchanges := []ports.ExchangePort{uc.liveExchange, uc.testExchange}
+	uc.mu.Unlock()
+
+	for _, exchange := range exchanges {
+		if exchange != nil {
+			uc.resizeWorkerPool(exchange)
+		}
+	}
+}
+
+func validateExchange(cfg config.ExchangeConfig) error {
+	if cfg.Name == "" || cfg.Host == "" || cfg.Port < 1 || cfg.Port > 65535 {
+		return fmt.Errorf("%w: name, host and a port between 1 and 65535 are required", ErrInvalidExchange)
+	}
+	return nil
+}
+
+// watchFeeds resizes a source's worker pool whenever one of its upstream feeds
+// changes state, e.g. gives up reconnecting or comes back
+func (uc *DataProcessingUseCase) watchFeeds(ctx context.Context, exchange ports.ExchangePort, changes <-chan struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+			uc.resizeWorkerPool(exchange)
+		}
+	}
+}
+
+// isActiveFeed reports whether a feed in the given state gets a share of the
+// worker pool. Feeds reconnecting or backing off keep theirs, so the pool
+// doesn't shrink and grow with every dropped connection; feeds that failed,
+// were paused or disabled, or whose source is stopped don't.
+func isActiveFeed(state models.ConnectionState) bool {
+	switch state {
+	case models.ConnectionStateConnected, models.ConnectionStateConnecting,
+		models.ConnectionStateStale, models.ConnectionStateBackingOff:
+		return true
+	}
+	return false
+}
+
+// workersFor sizes a source's worker pool by its number of active upstream feeds
+func (uc *DataProcessingUseCase) workersFor(exchange ports.ExchangePort) int {
+	feeds := 0
+	for _, status := range exchange.GetStatus() {
+		if isActiveFeed(status.State) {
+			feeds++
+		}
+	}
+	if feeds < 1 {
+		feeds = 1
+	}
+
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	return uc.workersPerExchange * feeds
+}
@@ -0,0 +1,90 @@
+package usecases
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"marketflow/internal/application/ports"
+	"marketflow/internal/concurrency"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+)
+
+// feedSource is a live source whose upstream states the test sets
+type feedSource struct {
+	ports.ExchangePort
+
+	mu      sync.Mutex
+	states  []models.ConnectionState
+	changes chan struct{}
+}
+
+func (s *feedSource) Start(ctx context.Context) (<-chan models.PriceUpdate, error) {
+	return make(chan models.PriceUpdate), nil
+}
+
+func (s *feedSource) GetName() string { return "live" }
+
+func (s *feedSource) GetStatus() []models.ExchangeStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]models.ExchangeStatus, 0, len(s.states))
+	for _, state := range s.states {
+		statuses = append(statuses, models.ExchangeStatus{State: state})
+	}
+	return statuses
+}
+
+func (s *feedSource) FeedChanges() <-chan struct{} { return s.changes }
+
+func (s *feedSource) set(i int, state models.ConnectionState) {
+	s.mu.Lock()
+	s.states[i] = state
+	s.mu.Unlock()
+	s.changes <- struct{}{}
+}
+
+func TestWorkerPoolFollowsFeedStateChanges(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	manager := concurrency.NewManager(logger)
+	uc := NewDataProcessingUseCase(nil, nil, manager, concurrency.NewBroadcaster(1, 1),
+		config.ProcessingConfig{WorkersPerExchange: 2}, nil, logger)
+
+	source := &feedSource{
+		states:  []models.ConnectionState{models.ConnectionStateConnected, models.ConnectionStateConnected, models.ConnectionStateConnecting},
+		changes: make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	uc.startPipeline(ctx, source)
+	defer manager.StopWorkerPool("live")
+
+	waitForWorkers := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for manager.WorkerPoolSizes()["live"] != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("%d workers, want %d", manager.WorkerPoolSizes()["live"], want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitForWorkers(6)
+
+	// Reconnecting feeds keep their share
+	source.set(0, models.ConnectionStateBackingOff)
+	source.set(1, models.ConnectionStateStale)
+	waitForWorkers(6)
+
+	// A feed that gave up reconnecting does not
+	source.set(0, models.ConnectionStateFailed)
+	waitForWorkers(4)
+
+	source.set(0, models.ConnectionStateConnected)
+	waitForWorkers(6)
+}
@@ -0,0 +1,173 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+)
+
+const (
+	defaultFailoverAfter         = 15 * time.Second
+	defaultFailbackAfter         = 10 * time.Second
+	defaultFailoverCheckInterval = time.Second
+	maxSourceTransitions         = 50
+)
+
+// failoverState tracks automatic failover from the live to the test source
+type failoverState struct {
+	cfg            config.FailoverConfig
+	active         bool
+	cancel         context.CancelFunc
+	unhealthySince time.Time
+	healthySince   time.Time
+	transitions    []models.SourceTransition
+}
+
+func newFailoverState(cfg config.FailoverConfig) failoverState {
+	if cfg.FailoverAfter <= 0 {
+		cfg.FailoverAfter = config.Duration(defaultFailoverAfter)
+	}
+	if cfg.FailbackAfter <= 0 {
+		cfg.FailbackAfter = config.Duration(defaultFailbackAfter)
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = config.Duration(defaultFailoverCheckInterval)
+	}
+
+	return failoverState{cfg: cfg}
+}
+
+// GetFailoverStatus returns the failover state and the recorded source transitions
+func (uc *DataProcessingUseCase) GetFailoverStatus() models.FailoverStatus {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+
+	activeSource := string(uc.mode)
+	if uc.failover.active {
+		activeSource = string(models.DataModeTest)
+	}
+
+	transitions := make([]models.SourceTransition, len(uc.failover.transitions))
+	copy(transitions, uc.failover.transitions)
+
+	return models.FailoverStatus{
+		Enabled:      uc.failover.cfg.Enabled,
+		Active:       uc.failover.active,
+		ActiveSource: activeSource,
+		Transitions:  transitions,
+	}
+}
+
+func (uc *DataProcessingUseCase) startFailoverMonitor(ctx context.Context) {
+	ticker := time.NewTicker(uc.failover.cfg.CheckInterval.Std())
+	defer ticker.Stop()
+
+	uc.logger.Info("Starting failover monitor",
+		"failover_after", uc.failover.cfg.FailoverAfter.Std(),
+		"failback_after", uc.failover.cfg.FailbackAfter.Std())
+
+	for {
+		select {
+		case <-ctx.Done():
+			uc.logger.Info("Failover monitor stopped")
+			return
+		case now := <-ticker.C:
+			uc.checkFailover(now)
+		}
+	}
+}
+
+func (uc *DataProcessingUseCase) checkFailover(now time.Time) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	f := &uc.failover
+	if !uc.isRunning || uc.mode != models.DataModeLive || uc.liveExchange == nil || uc.testExchange == nil {
+		return
+	}
+
+	healthy := uc.liveExchange.IsConnected()
+
+	if !f.active {
+		if healthy {
+			f.unhealthySince = time.Time{}
+			return
+		}
+		if f.unhealthySince.IsZero() {
+			f.unhealthySince = now
+		}
+		if down := now.Sub(f.unhealthySince); down >= f.cfg.FailoverAfter.Std() {
+			uc.activateFailover(now, fmt.Sprintf("live feeds unavailable for %s", down.Round(time.Second)))
+		}
+		return
+	}
+
+	if !healthy {
+		f.healthySince = time.Time{}
+		return
+	}
+	if f.healthySince.IsZero() {
+		f.healthySince = now
+	}
+	if up := now.Sub(f.healthySince); up >= f.cfg.FailbackAfter.Std() {
+		uc.deactivateFailover(now, fmt.Sprintf("live feeds healthy for %s", up.Round(time.Second)))
+	}
+}
+
+// activateFailover starts the test source next to the live one; callers must hold uc.mu
+func (uc *DataProcessingUseCase) activateFailover(now time.Time, reason string) {
+	ctx, cancel := context.WithCancel(uc.ctx)
+
+	uc.failover.active = true
+	uc.failover.cancel = cancel
+	uc.failover.unhealthySince = time.Time{}
+	uc.recordTransition(models.DataModeLive, models.DataModeTest, reason, now)
+
+	uc.logger.Warn("Failing over to test data", "reason", reason)
+	go uc.startPipeline(ctx, uc.testExchange)
+}
+
+// deactivateFailover stops the test source once live has recovered; callers must hold uc.mu
+func (uc *DataProcessingUseCase) deactivateFailover(now time.Time, reason string) {
+	uc.stopFailover()
+	uc.recordTransition(models.DataModeTest, models.DataModeLive, reason, now)
+
+	uc.logger.Info("Failing back to live data", "reason", reason)
+}
+
+// resetFailover drops any failover in progress; callers must hold uc.mu
+func (uc *DataProcessingUseCase) resetFailover() {
+	if uc.failover.active {
+		uc.stopFailover()
+	}
+	uc.failover.unhealthySince = time.Time{}
+	uc.failover.healthySince = time.Time{}
+}
+
+func (uc *DataProcessingUseCase) stopFailover() {
+	if uc.failover.cancel != nil {
+		uc.failover.cancel()
+		uc.failover.cancel = nil
+	}
+	uc.stopExchange(uc.testExchange)
+
+	uc.failover.active = false
+	uc.failover.healthySince = time.Time{}
+}
+
+// recordTransition appends to the bounded transition log; callers must hold uc.mu
+func (uc *DataProcessingUseCase) recordTransition(from, to models.DataMode, reason string, at time.Time) {
+	uc.failover.transitions = append(uc.failover.transitions, models.SourceTransition{
+		From:   string(from),
+		To:     string(to),
+		Reason: reason,
+		At:     at,
+	})
+
+	if excess := len(uc.failover.transitions) - maxSourceTransitions; excess > 0 {
+		uc.failover.transitions = uc.failover.transitions[excess:]
+	}
+}
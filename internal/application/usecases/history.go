@@ -0,0 +1,145 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+const (
+	// maxHistoryRange bounds the time range a single history query may span
+	maxHistoryRange = 31 * 24 * time.Hour
+
+	// DefaultHistoryRange is how far back history goes when no start time is given
+	DefaultHistoryRange = 24 * time.Hour
+
+	// DefaultHistoryLimit is the page size when none is requested
+	DefaultHistoryLimit = 100
+
+	// MaxHistoryLimit bounds the page size
+	MaxHistoryLimit = 1000
+)
+
+// HistoryPage is one page of aggregated history with the range it was read from
+type HistoryPage struct {
+	Data       []models.AggregatedData
+	From, To   time.Time
+	NextCursor string // empty on the last page
+}
+
+// GetHistory returns a page of the aggregated windows of a symbol starting
+// within [from, to), oldest first, and the cursor of the next page. A zero to
+// means now and a zero from DefaultHistoryRange before to. The cursor carries
+// the resolved range and the filters of the first page, so the following
+// pages read the same range; it is rejected with other parameters. An empty
+// cursor starts from the beginning.
+func (uc *MarketDataUseCase) GetHistory(ctx context.Context, symbol, exchange string, from, to time.Time, limit int, cursor string) (HistoryPage, error) {
+	if err := uc.checkRequest(symbol, exchange); err != nil {
+		return HistoryPage{}, err
+	}
+
+	var after *models.AggregatedCursor
+	if cursor != "" {
+		decoded, err := decodeCursor(cursor)
+		if err != nil {
+			return HistoryPage{}, err
+		}
+		if decoded.symbol != symbol || decoded.exchange != exchange ||
+			(!from.IsZero() && !from.Equal(decoded.from)) || (!to.IsZero() && !to.Equal(decoded.to)) {
+			return HistoryPage{}, fmt.Errorf("%w: it was issued for another symbol, exchange or time range", ErrInvalidCursor)
+		}
+		from, to = decoded.from, decoded.to
+		after = &decoded.after
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultHistoryRange)
+	}
+
+	if !from.Before(to) {
+		return HistoryPage{}, fmt.Errorf("%w: from must be before to", ErrInvalidRange)
+	}
+	if to.Sub(from) > maxHistoryRange {
+		return HistoryPage{}, fmt.Errorf("%w: at most %d days per request", ErrInvalidRange, maxHistoryRange/(24*time.Hour))
+	}
+	if limit < 1 || limit > MaxHistoryLimit {
+		return HistoryPage{}, fmt.Errorf("%w: must be between 1 and %d, got %d", ErrInvalidLimit, MaxHistoryLimit, limit)
+	}
+
+	// Fetch one extra row to know whether another page follows
+	data, err := uc.storage.GetAggregatedData(ctx, symbol, exchange, from, to, after, limit+1)
+	if err != nil {
+		return HistoryPage{}, err
+	}
+
+	page := HistoryPage{Data: data, From: from, To: to}
+	if len(data) > limit {
+		page.Data = data[:limit]
+		last := page.Data[len(page.Data)-1]
+		page.NextCursor = encodeCursor(historyCursor{
+			symbol:   symbol,
+			exchange: exchange,
+			from:     from,
+			to:       to,
+			after:    models.AggregatedCursor{WindowStart: last.WindowStart, ID: last.ID},
+		})
+	}
+	return page, nil
+}
+
+// historyCursor is the query of a history page and the last row it returned
+type historyCursor struct {
+	symbol, exchange string
+	from, to         time.Time
+	after            models.AggregatedCursor
+}
+
+// historyCursorJSON is the encoded form of a historyCursor, times in Unix nanoseconds
+type historyCursorJSON struct {
+	Symbol      string `json:"s"`
+	Exchange    string `json:"e,omitempty"`
+	From        int64  `json:"f"`
+	To          int64  `json:"t"`
+	WindowStart int64  `json:"w"`
+	ID          int64  `json:"i"`
+}
+
+// encodeCursor makes an opaque cursor of a history query and its last row
+func encodeCursor(cursor historyCursor) string {
+	raw, _ := json.Marshal(historyCursorJSON{
+		Symbol:      cursor.symbol,
+		Exchange:    cursor.exchange,
+		From:        cursor.from.UnixNano(),
+		To:          cursor.to.UnixNano(),
+		WindowStart: cursor.after.WindowStart.UnixNano(),
+		ID:          cursor.after.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(cursor string) (historyCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return historyCursor{}, fmt.Errorf("%w: %q", ErrInvalidCursor, cursor)
+	}
+
+	var decoded historyCursorJSON
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.Symbol == "" {
+		return historyCursor{}, fmt.Errorf("%w: %q", ErrInvalidCursor, cursor)
+	}
+
+	return historyCursor{
+		symbol:   decoded.Symbol,
+		exchange: decoded.Exchange,
+		from:     time.Unix(0, decoded.From),
+		to:       time.Unix(0, decoded.To),
+		after:    models.AggregatedCursor{WindowStart: time.Unix(0, decoded.WindowStart), ID: decoded.ID},
+	}, nil
+}
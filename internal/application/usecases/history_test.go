@@ -0,0 +1,123 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"marketflow/internal/application/ports"
+	"marketflow/internal/domain/models"
+	"marketflow/internal/domain/symbols"
+)
+
+// historyStorage serves GetAggregatedData from rows kept oldest first
+type historyStorage struct {
+	ports.StoragePort
+	rows []models.AggregatedData
+}
+
+func (s *historyStorage) GetAggregatedData(ctx context.Context, symbol, exchange string, from, to time.Time, after *models.AggregatedCursor, limit int) ([]models.AggregatedData, error) {
+	var page []models.AggregatedData
+	for _, row := range s.rows {
+		if row.PairName != symbol || (exchange != "" && row.Exchange != exchange) ||
+			row.WindowStart.Before(from) || !row.WindowStart.Before(to) {
+			continue
+		}
+		if after != nil && (row.WindowStart.Before(after.WindowStart) ||
+			(row.WindowStart.Equal(after.WindowStart) && row.ID <= after.ID)) {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, row)
+	}
+	return page, nil
+}
+
+func newHistoryUseCase(t *testing.T, storage ports.StoragePort) *MarketDataUseCase {
+	t.Helper()
+	registry, err := symbols.NewRegistry([]models.Symbol{
+		{Name: "BTCUSDT", Base: "BTC", Quote: "USDT", Precision: 2},
+		{Name: "ETHUSDT", Base: "ETH", Quote: "USDT", Precision: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewMarketDataUseCase(storage, nil, nil, registry, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestGetHistoryCursorKeepsTheRangeOfTheFirstPage(t *testing.T) {
+	storage := &historyStorage{}
+	now := time.Now().Truncate(time.Minute)
+	for i := 5; i > 0; i-- {
+		start := now.Add(-time.Duration(i) * time.Minute)
+		storage.rows = append(storage.rows, models.AggregatedData{ID: int64(6 - i), PairName: "BTCUSDT", WindowStart: start})
+	}
+	uc := newHistoryUseCase(t, storage)
+	ctx := context.Background()
+
+	first, err := uc.GetHistory(ctx, "BTCUSDT", "", time.Time{}, time.Time{}, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Data) != 2 || first.NextCursor == "" {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+
+	// A window written after the first page falls after its resolved end
+	storage.rows = append(storage.rows, models.AggregatedData{ID: 6, PairName: "BTCUSDT", WindowStart: first.To.Add(time.Millisecond)})
+
+	var ids []int64
+	for _, row := range first.Data {
+		ids = append(ids, row.ID)
+	}
+	page := first
+	for page.NextCursor != "" {
+		time.Sleep(time.Millisecond)
+		page, err = uc.GetHistory(ctx, "BTCUSDT", "", time.Time{}, time.Time{}, 2, page.NextCursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !page.From.Equal(first.From) || !page.To.Equal(first.To) {
+			t.Fatalf("page range [%s, %s) differs from the first page [%s, %s)", page.From, page.To, first.From, first.To)
+		}
+		for _, row := range page.Data {
+			ids = append(ids, row.ID)
+		}
+	}
+	if len(ids) != 5 {
+		t.Fatalf("got rows %v, want 1 to 5", ids)
+	}
+
+	// The cursor only continues the query it was issued for
+	for name, query := range map[string]struct {
+		symbol   string
+		from, to time.Time
+	}{
+		"symbol": {symbol: "ETHUSDT"},
+		"from":   {symbol: "BTCUSDT", from: first.From.Add(time.Minute)},
+		"to":     {symbol: "BTCUSDT", to: first.To.Add(time.Minute)},
+	} {
+		_, err := uc.GetHistory(ctx, query.symbol, "", query.from, query.to, 2, first.NextCursor)
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("other %s: got %v, want %v", name, err, ErrInvalidCursor)
+		}
+	}
+	decoded, err := decodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded.exchange = "exchange1"
+	if _, err := uc.GetHistory(ctx, "BTCUSDT", "", time.Time{}, time.Time{}, 2, encodeCursor(decoded)); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("other exchange: got %v, want %v", err, ErrInvalidCursor)
+	}
+
+	// Repeating the range of the first page is fine
+	if _, err := uc.GetHistory(ctx, "BTCUSDT", "", first.From, first.To, 2, first.NextCursor); err != nil {
+		t.Fatalf("same range: %v", err)
+	}
+}
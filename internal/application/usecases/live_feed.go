@@ -0,0 +1,174 @@
+package usecases
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+// spreadMaxAge is how long a price counts towards the spread of its symbol
+const spreadMaxAge = 10 * time.Second
+
+// liveFeed turns the price broadcast into ticks and spreads, and the candles
+// completed by the pipeline into candle messages, for subscription API listeners
+type liveFeed struct {
+	mu        sync.Mutex
+	latest    map[string]map[string]models.PriceUpdate // symbol -> exchange -> update
+	listeners map[*feedListener]struct{}
+}
+
+type feedListener struct {
+	fn func(models.StreamMessage)
+}
+
+func newLiveFeed() *liveFeed {
+	return &liveFeed{
+		latest:    make(map[string]map[string]models.PriceUpdate),
+		listeners: make(map[*feedListener]struct{}),
+	}
+}
+
+// ListenLiveFeed calls fn with every tick, completed candle and spread until
+// the returned function is called. fn must not block; ticks and spreads are
+// published from one goroutine and candles from the pipeline's flusher, never
+// at the same time.
+func (uc *MarketDataUseCase) ListenLiveFeed(fn func(models.StreamMessage)) (stop func()) {
+	listener := &feedListener{fn: fn}
+
+	uc.feed.mu.Lock()
+	uc.feed.listeners[listener] = struct{}{}
+	uc.feed.mu.Unlock()
+
+	return func() {
+		uc.feed.mu.Lock()
+		delete(uc.feed.listeners, listener)
+		uc.feed.mu.Unlock()
+	}
+}
+
+// RunLiveFeed follows the price broadcast and publishes the live feed until ctx is done
+func (uc *MarketDataUseCase) RunLiveFeed(ctx context.Context) {
+	var lastID uint64
+	resume := false
+	for {
+		sub, backlog := uc.stream.Subscribe(nil, lastID, resume)
+		for _, event := range backlog {
+			uc.feed.tick(event.Update)
+			lastID = event.ID
+		}
+
+	follow:
+		for {
+			select {
+			case <-ctx.Done():
+				sub.Close()
+				return
+			case event, ok := <-sub.Events():
+				if !ok {
+					break follow
+				}
+				uc.feed.tick(event.Update)
+				lastID = event.ID
+			}
+		}
+
+		// Evicted for falling behind: catch up from the replay buffer
+		uc.logger.Warn("Live feed fell behind the price broadcast, resuming", "error", sub.Err())
+		resume = true
+	}
+}
+
+// PublishCandles publishes candles completed by the processing pipeline, the
+// same ones it stores
+func (uc *MarketDataUseCase) PublishCandles(candles []models.Candle) {
+	for _, candle := range candles {
+		uc.feed.publish(models.StreamMessage{
+			Channel:  models.ChannelCandles,
+			Symbol:   candle.PairName,
+			Exchange: candle.Exchange,
+			Data:     candle,
+		})
+	}
+}
+
+// tick publishes an update and the spread it changes
+func (f *liveFeed) tick(update models.PriceUpdate) {
+	f.publish(models.StreamMessage{
+		Channel:  models.ChannelTicks,
+		Symbol:   update.Symbol,
+		Exchange: update.Exchange,
+		Data:     update,
+	})
+
+	if spread, ok := f.spread(update); ok {
+		f.publish(models.StreamMessage{
+			Channel: models.ChannelSpreads,
+			Symbol:  spread.Symbol,
+			Data:    spread,
+		})
+	}
+}
+
+// spread records update as the latest price of its exchange and returns the
+// spread of its symbol across the exchanges with a recent price
+func (f *liveFeed) spread(update models.PriceUpdate) (models.Spread, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	byExchange, ok := f.latest[update.Symbol]
+	if !ok {
+		byExchange = make(map[string]models.PriceUpdate)
+		f.latest[update.Symbol] = byExchange
+	}
+	byExchange[update.Exchange] = update
+
+	spread := models.Spread{Symbol: update.Symbol, Timestamp: update.ReceivedAt}
+	for exchange, latest := range byExchange {
+		if update.ReceivedAt.Sub(latest.ReceivedAt) > spreadMaxAge {
+			delete(byExchange, exchange)
+			continue
+		}
+		if spread.Exchanges == 0 || latest.Price > spread.High {
+			spread.High, spread.HighExchange = latest.Price, exchange
+		}
+		if spread.Exchanges == 0 || latest.Price < spread.Low {
+			spread.Low, spread.LowExchange = latest.Price, exchange
+		}
+		spread.Exchanges++
+	}
+	if spread.Exchanges < 2 {
+		return models.Spread{}, false
+	}
+
+	spread.Spread = spread.High - spread.Low
+	if spread.Low > 0 {
+		spread.SpreadBps = spread.Spread / spread.Low * 10000
+	}
+	return spread, true
+}
+
+func (f *liveFeed) publish(message models.StreamMessage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for listener := range f.listeners {
+		listener.fn(message)
+	}
+}
+
+// CheckStreamFilter rejects unknown symbols and exchanges before they are subscribed to
+func (uc *MarketDataUseCase) CheckStreamFilter(symbols, exchanges []string) error {
+	for _, symbol := range symbols {
+		if err := uc.checkSymbol(symbol); err != nil {
+			return err
+		}
+	}
+	for _, exchange := range exchanges {
+		if err := uc.checkExchange(exchange); err != nil {
+			return err
+		}
+	}
+	return nil
+}
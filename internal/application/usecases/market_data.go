@@ -2,31 +2,81 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"time"
 
 	"marketflow/internal/application/ports"
+	"marketflow/internal/concurrency"
+	"marketflow/internal/domain/aggregation"
 	"marketflow/internal/domain/models"
+	"marketflow/internal/domain/symbols"
 )
 
+var (
+	// ErrUnknownExchange is returned when a request names an exchange that is not configured
+	ErrUnknownExchange = errors.New("unknown exchange")
+
+	// ErrInvalidSymbol is returned when a request names a malformed symbol
+	ErrInvalidSymbol = errors.New("invalid symbol")
+
+	// ErrUnknownSymbol is returned when a request names a symbol that is not tracked
+	ErrUnknownSymbol = errors.New("unknown symbol")
+
+	// ErrInvalidInterval is returned when a request names a candle interval that is not built
+	ErrInvalidInterval = errors.New("invalid interval")
+
+	// ErrInvalidRange is returned when a time range is empty or too large
+	ErrInvalidRange = errors.New("invalid time range")
+
+	// ErrInvalidLimit is returned when a page size is out of bounds
+	ErrInvalidLimit = errors.New("invalid limit")
+
+	// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// maxCandlesPerRequest bounds the number of windows a single candle query may span
+const maxCandlesPerRequest = 10000
+
 // MarketDataUseCase handles market data operations
 type MarketDataUseCase struct {
-	storage ports.StoragePort
-	cache   ports.CachePort
-	logger  *slog.Logger
+	storage   ports.StoragePort
+	cache     ports.CachePort
+	sources   []ports.ExchangePort
+	symbols   *symbols.Registry
+	intervals []time.Duration
+	stream    *concurrency.Broadcaster
+	feed      *liveFeed
+	logger    *slog.Logger
 }
 
 // NewMarketDataUseCase creates a new MarketDataUseCase
-func NewMarketDataUseCase(storage ports.StoragePort, cache ports.CachePort, logger *slog.Logger) *MarketDataUseCase {
+func NewMarketDataUseCase(storage ports.StoragePort, cache ports.CachePort, sources []ports.ExchangePort, registry *symbols.Registry, candleIntervals []time.Duration, stream *concurrency.Broadcaster, logger *slog.Logger) *MarketDataUseCase {
 	return &MarketDataUseCase{
-		storage: storage,
-		cache:   cache,
-		logger:  logger,
+		storage:   storage,
+		cache:     cache,
+		sources:   sources,
+		symbols:   registry,
+		intervals: candleIntervals,
+		stream:    stream,
+		feed:      newLiveFeed(),
+		logger:    logger,
 	}
 }
 
+// GetExchanges returns the names of every exchange that can be queried
+func (uc *MarketDataUseCase) GetExchanges() []string {
+	return exchangeNames(uc.sources)
+}
+
 // GetLatestPrice returns the latest price for a symbol
 func (uc *MarketDataUseCase) GetLatestPrice(ctx context.Context, symbol, exchange string) (*models.LatestPrice, error) {
+	if err := uc.checkRequest(symbol, exchange); err != nil {
+		return nil, err
+	}
+
 	if exchange != "" {
 		return uc.cache.GetLatestPrice(ctx, symbol, exchange)
 	}
@@ -53,15 +103,103 @@ func (uc *MarketDataUseCase) GetLatestPrice(ctx context.Context, symbol, exchang
 
 // GetHighestPrice returns the highest price within a period
 func (uc *MarketDataUseCase) GetHighestPrice(ctx context.Context, symbol, exchange string, period time.Duration) (*models.AggregatedData, error) {
+	if err := uc.checkRequest(symbol, exchange); err != nil {
+		return nil, err
+	}
 	return uc.storage.GetHighestPrice(ctx, symbol, exchange, period)
 }
 
 // GetLowestPrice returns the lowest price within a period
 func (uc *MarketDataUseCase) GetLowestPrice(ctx context.Context, symbol, exchange string, period time.Duration) (*models.AggregatedData, error) {
+	if err := uc.checkRequest(symbol, exchange); err != nil {
+		return nil, err
+	}
 	return uc.storage.GetLowestPrice(ctx, symbol, exchange, period)
 }
 
 // GetAveragePrice returns the average price within a period
 func (uc *MarketDataUseCase) GetAveragePrice(ctx context.Context, symbol, exchange string, period time.Duration) (*models.AggregatedData, error) {
+	if err := uc.checkRequest(symbol, exchange); err != nil {
+		return nil, err
+	}
 	return uc.storage.GetAveragePrice(ctx, symbol, exchange, period)
 }
+
+// GetCandleIntervals returns the names of the intervals candles are built at
+func (uc *MarketDataUseCase) GetCandleIntervals() []string {
+	names := make([]string, 0, len(uc.intervals))
+	for _, interval := range uc.intervals {
+		names = append(names, aggregation.IntervalName(interval))
+	}
+	return names
+}
+
+// GetCandles returns the candles of a symbol opened within [from, to)
+func (uc *MarketDataUseCase) GetCandles(ctx context.Context, symbol, exchange, interval string, from, to time.Time) ([]models.Candle, error) {
+	if err := uc.checkRequest(symbol, exchange); err != nil {
+		return nil, err
+	}
+
+	var width time.Duration
+	for _, candidate := range uc.intervals {
+		if aggregation.IntervalName(candidate) == interval {
+			width = candidate
+		}
+	}
+	if width == 0 {
+		return nil, fmt.Errorf("%w %q: candles are built at %v", ErrInvalidInterval, interval, uc.GetCandleIntervals())
+	}
+
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidRange)
+	}
+	if to.Sub(from)/width > maxCandlesPerRequest {
+		return nil, fmt.Errorf("%w: at most %d %s candles per request", ErrInvalidRange, maxCandlesPerRequest, interval)
+	}
+
+	return uc.storage.GetCandles(ctx, symbol, exchange, interval, from, to)
+}
+
+// checkRequest rejects unknown exchanges and symbols before anything is queried
+func (uc *MarketDataUseCase) checkRequest(symbol, exchange string) error {
+	if err := uc.checkSymbol(symbol); err != nil {
+		return err
+	}
+	return uc.checkExchange(exchange)
+}
+
+// checkSymbol rejects malformed symbols and symbols missing from the registry
+func (uc *MarketDataUseCase) checkSymbol(symbol string) error {
+	if !symbols.ValidName(symbol) {
+		return fmt.Errorf("%w: %q", ErrInvalidSymbol, symbol)
+	}
+	if _, ok := uc.symbols.Get(symbol); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownSymbol, symbol)
+	}
+	return nil
+}
+
+// checkExchange rejects exchange names that no source publishes; empty means all exchanges
+func (uc *MarketDataUseCase) checkExchange(exchange string) error {
+	if exchange == "" {
+		return nil
+	}
+
+	for _, name := range uc.GetExchanges() {
+		if name == exchange {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownExchange, exchange)
+}
+
+// exchangeNames collects the exchange names published by a set of sources
+func exchangeNames(sources []ports.ExchangePort) []string {
+	var names []string
+	for _, source := range sources {
+		if source != nil {
+			names = append(names, source.GetExchanges()...)
+		}
+	}
+	return names
+}
@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"marketflow/internal/domain/aggregation"
+	"marketflow/internal/domain/models"
+)
+
+// recoverWindows rebuilds the windows a previous run had not persisted from
+// the price history in the cache. Windows that have closed are stored, the
+// window in progress stays open and is completed by the aggregation ticker.
+// It must run before the pipeline starts, so no update is counted twice, and
+// returns the start of the window in progress.
+func (uc *DataProcessingUseCase) recoverWindows(ctx context.Context, exchanges []string) time.Time {
+	now := time.Now()
+	current := now.Truncate(uc.interval)
+
+	recovered := 0
+	if uc.cache.Health().State == models.DependencyUp {
+		recovered = uc.recoverFromHistory(ctx, exchanges, now)
+	} else {
+		uc.logger.Warn("Cache unavailable, skipping window recovery")
+	}
+
+	// Closing at the current window also sets the watermark, so updates of
+	// windows before it are treated as late even when nothing was recovered
+	closed := uc.windows.Close(current)
+	uc.saveAggregated(ctx, closed)
+
+	uc.logger.Info("Recovered aggregation windows",
+		"updates", recovered, "persisted_windows", len(closed),
+		"open_windows", uc.windows.OpenWindows(), "current_window_start", current)
+	return current
+}
+
+// recoverFromHistory adds the cached updates of the windows that were not
+// persisted and returns how many were added
+func (uc *DataProcessingUseCase) recoverFromHistory(ctx context.Context, exchanges []string, now time.Time) int {
+	// The oldest window whose updates are all still in the cache; by event time
+	// its updates may have been received up to the allowed lateness earlier
+	oldest := now.Add(-(uc.historyRetention - uc.windowing.lateness)).Truncate(uc.interval).Add(uc.interval)
+
+	// Without the database, recover everything still cached; windows that
+	// were already persisted are upserted to the same values
+	databaseUp := uc.storage.Health().State == models.DependencyUp
+	if !databaseUp {
+		uc.logger.Warn("Database unavailable, recovering all cached windows")
+	}
+
+	recovered := 0
+	for _, symbol := range uc.symbols.Names() {
+		for _, exchange := range exchanges {
+			var persisted time.Time
+			if databaseUp {
+				var err error
+				persisted, err = uc.storage.GetLastWindowEnd(ctx, symbol, exchange)
+				if err != nil {
+					uc.logger.Warn("Failed to find last persisted window, recovering all cached windows",
+						"error", err, "symbol", symbol, "exchange", exchange)
+				}
+			}
+
+			start := oldest
+			if persisted.After(start) {
+				start = persisted
+			}
+
+			// By event time, an update may have been received up to the allowed
+			// lateness before its window opened
+			from := start
+			if uc.windowing.source == aggregation.TimeEvent {
+				from = start.Add(-uc.windowing.lateness)
+			}
+
+			history, err := uc.cache.GetPriceHistory(ctx, symbol, exchange, from, now)
+			if err != nil {
+				uc.logger.Warn("Failed to read price history, skipping recovery",
+					"error", err, "symbol", symbol, "exchange", exchange)
+				continue
+			}
+
+			for _, update := range history {
+				at := uc.windowing.timeOf(update)
+				if at.Before(start) {
+					// Already persisted, or part of a window the cache no longer holds in full
+					continue
+				}
+				if uc.windows.Add(update, at) {
+					recovered++
+				}
+			}
+		}
+	}
+	return recovered
+}
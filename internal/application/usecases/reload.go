@@ -0,0 +1,195 @@
+package usecases
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+	"marketflow/internal/domain/symbols"
+)
+
+// ConfigLoader re-reads the configuration from its sources
+type ConfigLoader func() (*config.Config, error)
+
+// ReloadUseCase applies configuration changes to the running pipeline
+type ReloadUseCase struct {
+	mu             sync.Mutex
+	load           ConfigLoader
+	current        *config.Config
+	dataProcessing *DataProcessingUseCase
+	symbols        *symbols.Registry
+	logLevel       *slog.LevelVar
+	logger         *slog.Logger
+}
+
+// NewReloadUseCase creates a new ReloadUseCase; current is the configuration the service started with
+func NewReloadUseCase(load ConfigLoader, current *config.Config, dataProcessing *DataProcessingUseCase, registry *symbols.Registry, logLevel *slog.LevelVar, logger *slog.Logger) *ReloadUseCase {
+	return &ReloadUseCase{
+		load:           load,
+		current:        current,
+		dataProcessing: dataProcessing,
+		symbols:        registry,
+		logLevel:       logLevel,
+		logger:         logger,
+	}
+}
+
+// Reload re-reads the configuration, applies the changes that are safe at runtime
+// and reports the ones that only take effect after a restart
+func (uc *ReloadUseCase) Reload() (models.ReloadResult, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	next, err := uc.load()
+	if err != nil {
+		return models.ReloadResult{}, err
+	}
+
+	cur := uc.current
+	result := models.ReloadResult{
+		File:            next.File,
+		Applied:         []string{},
+		RestartRequired: []string{},
+		ReloadedAt:      time.Now(),
+	}
+
+	// running tracks what is actually in effect; sections needing a restart keep their old values
+	running := *next
+
+	restartOnly := []struct {
+		key       string
+		old, next interface{}
+		keep      func()
+	}{
+		{"server", cur.Server, next.Server, func() { running.Server = cur.Server }},
+		{"database", cur.Database, next.Database, func() { running.Database = cur.Database }},
+		{"exchanges.test", cur.Exchanges.Test, next.Exchanges.Test, func() { running.Exchanges.Test = cur.Exchanges.Test }},
+		{"exchanges.reconnect", cur.Exchanges.Reconnect, next.Exchanges.Reconnect, func() { running.Exchanges.Reconnect = cur.Exchanges.Reconnect }},
+		{"exchanges.stale_timeout", cur.Exchanges.StaleTimeout, next.Exchanges.StaleTimeout, func() { running.Exchanges.StaleTimeout = cur.Exchanges.StaleTimeout }},
+		{"exchanges.backpressure", cur.Exchanges.Backpressure, next.Exchanges.Backpressure, func() { running.Exchanges.Backpressure = cur.Exchanges.Backpressure }},
+		{"processing.aggregation_interval", cur.Processing.AggregationInterval, next.Processing.AggregationInterval, func() { running.Processing.AggregationInterval = cur.Processing.AggregationInterval }},
+		{"processing.candle_intervals", cur.Processing.CandleIntervals, next.Processing.CandleIntervals, func() { running.Processing.CandleIntervals = cur.Processing.CandleIntervals }},
+		{"processing.windowing", cur.Processing.Windowing, next.Processing.Windowing, func() { running.Processing.Windowing = cur.Processing.Windowing }},
+		{"processing.failover", cur.Processing.Failover, next.Processing.Failover, func() { running.Processing.Failover = cur.Processing.Failover }},
+	}
+	for _, section := range restartOnly {
+		if !reflect.DeepEqual(section.old, section.next) {
+			result.RestartRequired = append(result.RestartRequired, section.key)
+			section.keep()
+		}
+	}
+
+	if cur.Logging.Level != next.Logging.Level {
+		level, _ := next.Logging.SlogLevel()
+		uc.logLevel.Set(level)
+		result.Applied = append(result.Applied, "logging.level")
+	}
+
+	if cur.Processing.WorkersPerExchange != next.Processing.WorkersPerExchange {
+		uc.dataProcessing.SetWorkersPerExchange(next.Processing.WorkersPerExchange)
+		result.Applied = append(result.Applied, "processing.workers_per_exchange")
+	}
+
+	if cur.Processing.BatchSize != next.Processing.BatchSize {
+		uc.dataProcessing.SetBatchSize(next.Processing.BatchSize)
+		result.Applied = append(result.Applied, "processing.batch_size")
+	}
+
+	// A changed symbol list replaces the registry, including symbols added through the admin API
+	if !reflect.DeepEqual(cur.Symbols, next.Symbols) {
+		if err := uc.symbols.Replace(TrackedSymbols(next.Symbols)); err != nil {
+			result.Failed = append(result.Failed, fmt.Sprintf("symbols: %v", err))
+			running.Symbols = cur.Symbols
+		} else {
+			result.Applied = append(result.Applied, "symbols")
+		}
+	}
+
+	running.Exchanges.Live = uc.reloadExchanges(cur.Exchanges.Live, next.Exchanges.Live, &result)
+
+	uc.current = &running
+
+	uc.logger.Info("Configuration reloaded",
+		"file", result.File,
+		"applied", result.Applied,
+		"restart_required", result.RestartRequired,
+		"failed", result.Failed)
+	return result, nil
+}
+
+// reloadExchanges adds, updates and removes the live feeds whose definition
+// changed in the file and returns the definitions now in effect. Feeds the file
+// leaves unchanged keep any state set through the exchanges API; for the others
+// the operation is chosen by the feeds actually running, so a feed attached
+// through the API that now appears in the file is updated, and one detached
+// through the API is added back if its definition changes.
+func (uc *ReloadUseCase) reloadExchanges(old, next []config.ExchangeConfig, result *models.ReloadResult) []config.ExchangeConfig {
+	inEffect := make(map[string]config.ExchangeConfig, len(old))
+	for _, exchange := range old {
+		inEffect[exchange.Name] = exchange
+	}
+
+	running := make(map[string]bool)
+	for _, status := range uc.dataProcessing.ListExchanges() {
+		running[status.Name] = true
+	}
+
+	wanted := make(map[string]bool, len(next))
+	for _, exchange := range next {
+		wanted[exchange.Name] = true
+
+		if previous, exists := inEffect[exchange.Name]; exists && reflect.DeepEqual(previous, exchange) {
+			continue
+		}
+
+		var err error
+		if running[exchange.Name] {
+			err = uc.dataProcessing.UpdateExchange(exchange)
+		} else {
+			err = uc.dataProcessing.AddExchange(exchange)
+		}
+
+		key := fmt.Sprintf("exchanges.live[%s]", exchange.Name)
+		if err != nil {
+			result.Failed = append(result.Failed, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		inEffect[exchange.Name] = exchange
+		result.Applied = append(result.Applied, key)
+	}
+
+	for _, exchange := range old {
+		if wanted[exchange.Name] {
+			continue
+		}
+
+		key := fmt.Sprintf("exchanges.live[%s]", exchange.Name)
+		if running[exchange.Name] {
+			if err := uc.dataProcessing.RemoveExchange(exchange.Name); err != nil {
+				result.Failed = append(result.Failed, fmt.Sprintf("%s: %v", key, err))
+				continue
+			}
+		}
+		delete(inEffect, exchange.Name)
+		result.Applied = append(result.Applied, key)
+	}
+
+	// Keep the order of the new file, followed by any feed that could not be removed
+	live := make([]config.ExchangeConfig, 0, len(inEffect))
+	for _, exchange := range next {
+		if cfg, ok := inEffect[exchange.Name]; ok {
+			live = append(live, cfg)
+			delete(inEffect, exchange.Name)
+		}
+	}
+	for _, exchange := range old {
+		if cfg, ok := inEffect[exchange.Name]; ok {
+			live = append(live, cfg)
+		}
+	}
+	return live
+}
@@ -0,0 +1,131 @@
+package usecases
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"testing"
+
+	"marketflow/internal/application/ports"
+	"marketflow/internal/concurrency"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+)
+
+// managedSource is a live source whose feeds are only changed through ExchangeManager
+type managedSource struct {
+	ports.ExchangePort
+	feeds map[string]config.ExchangeConfig
+}
+
+func (s *managedSource) GetName() string { return "live" }
+
+func (s *managedSource) GetStatus() []models.ExchangeStatus {
+	statuses := make([]models.ExchangeStatus, 0, len(s.feeds))
+	for name, cfg := range s.feeds {
+		statuses = append(statuses, models.ExchangeStatus{Name: name, Address: fmt.Sprintf("%s:%d", cfg.Host, cfg.Port), State: models.ConnectionStateConnected})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func (s *managedSource) AddExchange(cfg config.ExchangeConfig) error {
+	if _, ok := s.feeds[cfg.Name]; ok {
+		return fmt.Errorf("%w: %s", ports.ErrExchangeExists, cfg.Name)
+	}
+	s.feeds[cfg.Name] = cfg
+	return nil
+}
+
+func (s *managedSource) UpdateExchange(cfg config.ExchangeConfig) error {
+	if _, ok := s.feeds[cfg.Name]; !ok {
+		return fmt.Errorf("%w: %s", ports.ErrExchangeNotFound, cfg.Name)
+	}
+	s.feeds[cfg.Name] = cfg
+	return nil
+}
+
+func (s *managedSource) RemoveExchange(name string) error {
+	if _, ok := s.feeds[name]; !ok {
+		return fmt.Errorf("%w: %s", ports.ErrExchangeNotFound, name)
+	}
+	delete(s.feeds, name)
+	return nil
+}
+
+func (s *managedSource) PauseExchange(name string) error  { return nil }
+func (s *managedSource) ResumeExchange(name string) error { return nil }
+
+func exchange(name string, port int) config.ExchangeConfig {
+	return config.ExchangeConfig{Name: name, Host: "127.0.0.1", Port: port}
+}
+
+func TestReloadExchangesDiffsAgainstTheRunningFeeds(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	source := &managedSource{feeds: map[string]config.ExchangeConfig{}}
+	dataProcessing := NewDataProcessingUseCase(nil, nil, concurrency.NewManager(logger), nil, config.ProcessingConfig{}, nil, logger)
+	dataProcessing.liveExchange = source
+
+	started := config.Defaults()
+	started.Exchanges.Live = []config.ExchangeConfig{exchange("exchange1", 40101), exchange("exchange2", 40102), exchange("exchange3", 40103)}
+	for _, cfg := range started.Exchanges.Live {
+		source.feeds[cfg.Name] = cfg
+	}
+
+	next := config.Defaults()
+	reload := NewReloadUseCase(func() (*config.Config, error) {
+		cfg := *next
+		return &cfg, nil
+	}, started, dataProcessing, nil, nil, logger)
+
+	// Through the API: exchange4 is attached, exchange2 detached and exchange3 moved
+	if err := dataProcessing.AddExchange(exchange("exchange4", 40104)); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataProcessing.RemoveExchange("exchange2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataProcessing.UpdateExchange(exchange("exchange3", 40113)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file now lists exchange4, moves exchange2 and drops exchange1; exchange3 is unchanged
+	next.Exchanges.Live = []config.ExchangeConfig{exchange("exchange2", 40202), exchange("exchange3", 40103), exchange("exchange4", 40204)}
+
+	result, err := reload.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Failed) != 0 {
+		t.Fatalf("failed: %v", result.Failed)
+	}
+	applied := []string{"exchanges.live[exchange1]", "exchanges.live[exchange2]", "exchanges.live[exchange4]"}
+	sort.Strings(result.Applied)
+	if !slices.Equal(result.Applied, applied) {
+		t.Fatalf("applied %v, want %v", result.Applied, applied)
+	}
+
+	want := map[string]int{"exchange2": 40202, "exchange3": 40113, "exchange4": 40204}
+	if len(source.feeds) != len(want) {
+		t.Fatalf("running %v, want %v", source.feeds, want)
+	}
+	for name, port := range want {
+		if source.feeds[name].Port != port {
+			t.Errorf("%s runs on port %d, want %d", name, source.feeds[name].Port, port)
+		}
+	}
+
+	// Dropping a feed from the file that was already detached through the API is not an error
+	if err := dataProcessing.RemoveExchange("exchange3"); err != nil {
+		t.Fatal(err)
+	}
+	next.Exchanges.Live = []config.ExchangeConfig{exchange("exchange2", 40202), exchange("exchange4", 40204)}
+	if result, err = reload.Reload(); err != nil || len(result.Failed) != 0 {
+		t.Fatalf("reload: %v %v", err, result.Failed)
+	}
+	if len(source.feeds) != 2 {
+		t.Fatalf("running %v, want exchange2 and exchange4", source.feeds)
+	}
+}
@@ -0,0 +1,42 @@
+package usecases
+
+import (
+	"marketflow/internal/concurrency"
+	"marketflow/internal/domain/models"
+)
+
+// SubscribePrices subscribes to the live price updates of the given symbols
+// and exchanges; empty lists mean all of them. With resume set, the buffered
+// updates published after lastID are returned to be sent first.
+func (uc *MarketDataUseCase) SubscribePrices(symbols, exchanges []string, lastID uint64, resume bool) (*concurrency.Subscription, []concurrency.Event, error) {
+	if err := uc.CheckStreamFilter(symbols, exchanges); err != nil {
+		return nil, nil, err
+	}
+
+	sub, backlog := uc.stream.Subscribe(priceFilter(symbols, exchanges), lastID, resume)
+	return sub, backlog, nil
+}
+
+// priceFilter accepts updates for one of the symbols from one of the exchanges
+func priceFilter(symbols, exchanges []string) func(models.PriceUpdate) bool {
+	if len(symbols) == 0 && len(exchanges) == 0 {
+		return nil
+	}
+
+	symbolSet := toSet(symbols)
+	exchangeSet := toSet(exchanges)
+	return func(update models.PriceUpdate) bool {
+		if len(symbolSet) > 0 && !symbolSet[update.Symbol] {
+			return false
+		}
+		return len(exchangeSet) == 0 || exchangeSet[update.Exchange]
+	}
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
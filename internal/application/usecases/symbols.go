@@ -0,0 +1,72 @@
+package usecases
+
+import (
+	"log/slog"
+
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+	"marketflow/internal/domain/symbols"
+)
+
+// TrackedSymbols converts the configured symbols to domain symbols
+func TrackedSymbols(list []config.SymbolConfig) []models.Symbol {
+	tracked := make([]models.Symbol, 0, len(list))
+	for _, symbol := range list {
+		precision := symbols.DefaultPrecision
+		if symbol.Precision != nil {
+			precision = *symbol.Precision
+		}
+		tracked = append(tracked, models.Symbol{
+			Name:      symbol.Name,
+			Base:      symbol.Base,
+			Quote:     symbol.Quote,
+			Precision: precision,
+		})
+	}
+	return tracked
+}
+
+// SymbolsUseCase manages the registry of tracked symbols
+type SymbolsUseCase struct {
+	registry *symbols.Registry
+	logger   *slog.Logger
+}
+
+// NewSymbolsUseCase creates a new SymbolsUseCase
+func NewSymbolsUseCase(registry *symbols.Registry, logger *slog.Logger) *SymbolsUseCase {
+	return &SymbolsUseCase{
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// ListSymbols returns every tracked symbol
+func (uc *SymbolsUseCase) ListSymbols() []models.Symbol {
+	return uc.registry.List()
+}
+
+// GetSymbol returns a tracked symbol by name
+func (uc *SymbolsUseCase) GetSymbol(name string) (models.Symbol, bool) {
+	return uc.registry.Get(name)
+}
+
+// AddSymbol starts tracking a symbol; the generator and aggregator pick it up on their next run
+func (uc *SymbolsUseCase) AddSymbol(symbol models.Symbol) (models.Symbol, error) {
+	added, err := uc.registry.Add(symbol)
+	if err != nil {
+		return added, err
+	}
+
+	uc.logger.Info("Symbol added", "symbol", added.Name, "base", added.Base, "quote", added.Quote, "precision", added.Precision)
+	return added, nil
+}
+
+// RemoveSymbol stops tracking a symbol
+func (uc *SymbolsUseCase) RemoveSymbol(name string) error {
+	if err := uc.registry.Remove(name); err != nil {
+		return err
+	}
+
+	uc.logger.Info("Symbol removed", "symbol", name)
+	return nil
+}
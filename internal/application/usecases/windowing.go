@@ -0,0 +1,215 @@
+package usecases
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"marketflow/internal/config"
+	"marketflow/internal/domain/aggregation"
+	"marketflow/internal/domain/models"
+)
+
+// defaultAllowedLateness is how long after a window closes it is aggregated
+// when no lateness is configured
+const defaultAllowedLateness = time.Second
+
+// windowingState places updates in aggregation windows and tracks updates
+// that arrive after their window was aggregated
+type windowingState struct {
+	source   aggregation.TimeSource
+	lateness time.Duration
+	policy   aggregation.LatePolicy
+
+	statsMu sync.Mutex
+	counts  map[string]*models.LateCounter
+	skewed  uint64
+}
+
+func newWindowingState(cfg config.WindowingConfig) *windowingState {
+	source := aggregation.TimeSource(cfg.TimeSource)
+	if !source.IsValid() {
+		source = aggregation.TimeReceived
+	}
+	policy := aggregation.LatePolicy(cfg.LatePolicy)
+	if !policy.IsValid() {
+		policy = aggregation.LateDrop
+	}
+	lateness := cfg.AllowedLateness.Std()
+	if lateness < 0 {
+		lateness = defaultAllowedLateness
+	}
+
+	return &windowingState{
+		source:   source,
+		lateness: lateness,
+		policy:   policy,
+		counts:   make(map[string]*models.LateCounter),
+	}
+}
+
+// timeOf returns the time that places an update in a window. Event times more
+// than the allowed lateness ahead of the local clock fall back to the receive
+// time, so a bad exchange clock cannot hold windows open into the future
+func (w *windowingState) timeOf(update models.PriceUpdate) time.Time {
+	at := w.source.TimeOf(update)
+	if w.source == aggregation.TimeEvent && at.Sub(update.ReceivedAt) > w.lateness {
+		return update.ReceivedAt
+	}
+	return at
+}
+
+// counter returns the late counter of an exchange; callers must hold statsMu
+func (w *windowingState) counter(exchange string) *models.LateCounter {
+	c, ok := w.counts[exchange]
+	if !ok {
+		c = &models.LateCounter{Exchange: exchange}
+		w.counts[exchange] = c
+	}
+	return c
+}
+
+func (w *windowingState) recordLate(exchange string, err error) {
+	w.statsMu.Lock()
+	defer w.statsMu.Unlock()
+
+	c := w.counter(exchange)
+	c.Late++
+	switch {
+	case err != nil:
+		c.Failed++
+	case w.policy == aggregation.LateAmend:
+		c.Amended++
+	case w.policy == aggregation.LateCorrect:
+		c.Corrected++
+	default:
+		c.Dropped++
+	}
+}
+
+func (w *windowingState) recordLateCandle(exchange string, err error) {
+	w.statsMu.Lock()
+	defer w.statsMu.Unlock()
+
+	c := w.counter(exchange)
+	c.LateCandles++
+	if err != nil {
+		c.Failed++
+	}
+}
+
+func (w *windowingState) recordSkew(update models.PriceUpdate) {
+	if w.source != aggregation.TimeEvent || update.Timestamp <= 0 {
+		return
+	}
+	if time.UnixMilli(update.Timestamp).Sub(update.ReceivedAt) <= w.lateness {
+		return
+	}
+
+	w.statsMu.Lock()
+	w.skewed++
+	w.statsMu.Unlock()
+}
+
+// GetLatenessStats returns the windowing settings and the late update counters
+func (uc *DataProcessingUseCase) GetLatenessStats() models.LatenessStats {
+	w := uc.windowing
+	stats := models.LatenessStats{
+		TimeSource:      string(w.source),
+		AllowedLateness: w.lateness.String(),
+		LatePolicy:      string(w.policy),
+		Watermark:       uc.windows.Watermark(),
+		OpenWindows:     uc.windows.OpenWindows(),
+		Exchanges:       []models.LateCounter{},
+	}
+
+	w.statsMu.Lock()
+	defer w.statsMu.Unlock()
+
+	stats.Skewed = w.skewed
+	for _, c := range w.counts {
+		stats.Late += c.Late
+		stats.LateCandles += c.LateCandles
+		stats.Failed += c.Failed
+		stats.Exchanges = append(stats.Exchanges, *c)
+	}
+	sort.Slice(stats.Exchanges, func(i, j int) bool {
+		return stats.Exchanges[i].Exchange < stats.Exchanges[j].Exchange
+	})
+	return stats
+}
+
+// addToWindow folds an update placed at time at into its aggregation window
+// and reports whether it was late, i.e. its window had already been aggregated
+func (uc *DataProcessingUseCase) addToWindow(update models.PriceUpdate, at time.Time) bool {
+	uc.windowing.recordSkew(update)
+	return !uc.windows.Add(update, at)
+}
+
+// handleLateUpdate applies the late policy to an update whose window was already aggregated
+func (uc *DataProcessingUseCase) handleLateUpdate(ctx context.Context, update models.PriceUpdate, at time.Time) {
+	w := uc.windowing
+	start := at.Truncate(uc.interval)
+	end := start.Add(uc.interval)
+
+	var err error
+	switch w.policy {
+	case aggregation.LateAmend:
+		err = uc.storage.AmendAggregatedData(ctx, models.AggregatedData{
+			PairName:     update.Symbol,
+			Exchange:     update.Exchange,
+			Timestamp:    start,
+			WindowStart:  start,
+			WindowEnd:    end,
+			Interval:     aggregation.IntervalName(uc.interval),
+			AveragePrice: update.Price,
+			MinPrice:     update.Price,
+			MaxPrice:     update.Price,
+			TickCount:    1,
+		})
+	case aggregation.LateCorrect:
+		err = uc.storage.SaveCorrections(ctx, []models.Correction{{
+			PairName:    update.Symbol,
+			Exchange:    update.Exchange,
+			WindowStart: start,
+			WindowEnd:   end,
+			Price:       update.Price,
+			EventTime:   aggregation.TimeEvent.TimeOf(update),
+			ReceivedAt:  update.ReceivedAt,
+		}})
+	}
+	w.recordLate(update.Exchange, err)
+
+	if err != nil {
+		uc.logger.Error("Failed to apply late policy", "error", err, "policy", w.policy,
+			"symbol", update.Symbol, "exchange", update.Exchange, "window_start", start)
+		return
+	}
+
+	uc.logger.Debug("Late price update", "policy", w.policy,
+		"symbol", update.Symbol, "exchange", update.Exchange,
+		"window_start", start, "lateness", uc.windows.Watermark().Sub(at))
+}
+
+// handleLateCandles applies the late policy to an update whose candles were
+// already emitted: amend folds it into the stored candles, drop and correct
+// leave them as they are, since corrections are kept per aggregation window
+func (uc *DataProcessingUseCase) handleLateCandles(ctx context.Context, update models.PriceUpdate, candles []models.Candle) {
+	w := uc.windowing
+
+	var err error
+	if w.policy == aggregation.LateAmend {
+		err = uc.storage.AmendCandles(ctx, candles)
+	}
+	w.recordLateCandle(update.Exchange, err)
+
+	if err != nil {
+		uc.logger.Error("Failed to apply late policy to candles", "error", err, "policy", w.policy,
+			"symbol", update.Symbol, "exchange", update.Exchange, "candles", len(candles))
+		return
+	}
+
+	uc.logger.Debug("Late price update for emitted candles", "policy", w.policy,
+		"symbol", update.Symbol, "exchange", update.Exchange, "candles", len(candles))
+}
@@ -0,0 +1,85 @@
+package usecases
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"marketflow/internal/application/ports"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/models"
+)
+
+// discardCache accepts every latest price
+type discardCache struct {
+	ports.CachePort
+}
+
+func (discardCache) SetLatestPrice(ctx context.Context, update models.PriceUpdate) error { return nil }
+
+// amendStorage records the amendments of the late policy
+type amendStorage struct {
+	ports.StoragePort
+	windows []models.AggregatedData
+	candles []models.Candle
+}
+
+func (s *amendStorage) AmendAggregatedData(ctx context.Context, data models.AggregatedData) error {
+	s.windows = append(s.windows, data)
+	return nil
+}
+
+func (s *amendStorage) AmendCandles(ctx context.Context, candles []models.Candle) error {
+	s.candles = append(s.candles, candles...)
+	return nil
+}
+
+func TestEventTimePlacesWindowsAndCandlesAlike(t *testing.T) {
+	storage := &amendStorage{}
+	cfg := config.ProcessingConfig{
+		AggregationInterval: config.Duration(time.Minute),
+		CandleIntervals:     []config.Duration{config.Duration(time.Minute)},
+		Windowing: config.WindowingConfig{
+			TimeSource:      "event",
+			AllowedLateness: config.Duration(time.Second),
+			LatePolicy:      "amend",
+		},
+	}
+	uc := NewDataProcessingUseCase(storage, discardCache{}, nil, nil, cfg, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx := context.Background()
+	boundary := time.Date(2026, 1, 1, 12, 4, 0, 0, time.UTC)
+	previous := boundary.Add(-time.Minute)
+
+	// Sent before the boundary but received after it: both the window and the
+	// candle follow the event time
+	uc.processPriceUpdate(ctx, models.PriceUpdate{Symbol: "BTCUSDT", Exchange: "exchange1", Price: 100,
+		Timestamp: boundary.Add(-2 * time.Second).UnixMilli(), ReceivedAt: boundary.Add(500 * time.Millisecond)})
+
+	candles := uc.candles.Flush(boundary.Add(time.Second))
+	if len(candles) != 1 || !candles[0].OpenTime.Equal(previous) || candles[0].TickCount != 1 {
+		t.Fatalf("unexpected candles %+v", candles)
+	}
+	windows := uc.windows.Close(boundary)
+	if len(windows) != 1 || !windows[0].WindowStart.Equal(previous) || windows[0].TickCount != 1 {
+		t.Fatalf("unexpected windows %+v", windows)
+	}
+
+	// Past the allowed lateness the update is late for both and amends both
+	uc.processPriceUpdate(ctx, models.PriceUpdate{Symbol: "BTCUSDT", Exchange: "exchange1", Price: 90,
+		Timestamp: boundary.Add(-time.Second).UnixMilli(), ReceivedAt: boundary.Add(3 * time.Second)})
+
+	if len(storage.windows) != 1 || !storage.windows[0].WindowStart.Equal(previous) || storage.windows[0].MinPrice != 90 {
+		t.Fatalf("unexpected window amendments %+v", storage.windows)
+	}
+	if len(storage.candles) != 1 || !storage.candles[0].OpenTime.Equal(previous) || storage.candles[0].Close != 90 {
+		t.Fatalf("unexpected candle amendments %+v", storage.candles)
+	}
+
+	stats := uc.GetLatenessStats()
+	if stats.Late != 1 || stats.LateCandles != 1 || stats.Failed != 0 || len(stats.Exchanges) != 1 || stats.Exchanges[0].Amended != 1 {
+		t.Fatalf("unexpected lateness stats %+v", stats)
+	}
+}
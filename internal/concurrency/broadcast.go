@@ -0,0 +1,183 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+const (
+	defaultReplayBuffer = 1000
+	defaultClientBuffer = 256
+)
+
+// ErrSlowConsumer is the reason a subscription is closed when it falls a full buffer behind
+var ErrSlowConsumer = errors.New("subscriber too slow, evicted")
+
+// Event is a price update numbered in the order it was broadcast
+type Event struct {
+	ID     uint64
+	Update models.PriceUpdate
+}
+
+// Broadcaster delivers every published price update to its subscribers without
+// ever blocking the publisher: a subscriber whose buffer is full is evicted.
+// The most recent updates are kept so a subscriber can resume after the last
+// event it saw.
+type Broadcaster struct {
+	mu           sync.Mutex
+	subscribers  map[*Subscription]struct{}
+	replay       []Event // ring buffer of the latest events, oldest at start
+	start        int
+	nextID       uint64
+	clientBuffer int
+
+	published uint64
+	evicted   uint64
+}
+
+// NewBroadcaster creates a broadcaster that keeps replaySize events for resuming
+// and queues up to clientBuffer events per subscriber
+func NewBroadcaster(replaySize, clientBuffer int) *Broadcaster {
+	if replaySize < 1 {
+		replaySize = defaultReplayBuffer
+	}
+	if clientBuffer < 1 {
+		clientBuffer = defaultClientBuffer
+	}
+
+	return &Broadcaster{
+		subscribers: make(map[*Subscription]struct{}),
+		replay:      make([]Event, 0, replaySize),
+		// IDs start from the clock so they keep increasing across restarts and a
+		// client resuming with an ID from a previous run gets the whole buffer
+		nextID:       uint64(time.Now().UnixMicro()),
+		clientBuffer: clientBuffer,
+	}
+}
+
+// Run publishes every update received on input until it is closed or ctx is done
+func (b *Broadcaster) Run(ctx context.Context, input <-chan models.PriceUpdate) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-input:
+			if !ok {
+				return
+			}
+			b.Publish(update)
+		}
+	}
+}
+
+// Publish numbers an update, keeps it for replay and queues it for every matching subscriber
+func (b *Broadcaster) Publish(update models.PriceUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event := Event{ID: b.nextID, Update: update}
+	b.nextID++
+	b.published++
+
+	if len(b.replay) < cap(b.replay) {
+		b.replay = append(b.replay, event)
+	} else {
+		b.replay[b.start] = event
+		b.start = (b.start + 1) % len(b.replay)
+	}
+
+	for sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(update) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.evictLocked(sub, ErrSlowConsumer)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for the updates accepted by filter (nil
+// accepts all). With resume set, the buffered events after lastID are
+// returned so the subscriber can send them before anything on its channel;
+// nothing is lost or repeated between the two.
+func (b *Broadcaster) Subscribe(filter func(models.PriceUpdate) bool, lastID uint64, resume bool) (*Subscription, []Event) {
+	sub := &Subscription{
+		broadcaster: b,
+		filter:      filter,
+		events:      make(chan Event, b.clientBuffer),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []Event
+	if resume {
+		for i := range b.replay {
+			event := b.replay[(b.start+i)%len(b.replay)]
+			if event.ID > lastID && (filter == nil || filter(event.Update)) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	b.subscribers[sub] = struct{}{}
+	return sub, backlog
+}
+
+// Stats returns the number of subscribers and of updates published and subscribers evicted
+func (b *Broadcaster) Stats() models.StreamStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return models.StreamStats{
+		Subscribers: len(b.subscribers),
+		Published:   b.published,
+		Evicted:     b.evicted,
+		Buffered:    len(b.replay),
+	}
+}
+
+func (b *Broadcaster) evictLocked(sub *Subscription, reason error) {
+	delete(b.subscribers, sub)
+	sub.err = reason
+	close(sub.events)
+	if reason != nil {
+		b.evicted++
+	}
+}
+
+// Subscription receives the events of a Broadcaster
+type Subscription struct {
+	broadcaster *Broadcaster
+	filter      func(models.PriceUpdate) bool
+	events      chan Event
+	err         error // set before events is closed
+}
+
+// Events returns the channel events are delivered on; it is closed when the
+// subscription ends
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns why the subscription was closed by the broadcaster, once Events is closed
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Close ends the subscription
+func (s *Subscription) Close() {
+	b := s.broadcaster
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[s]; ok {
+		b.evictLocked(s, nil)
+	}
+}
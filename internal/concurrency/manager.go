@@ -49,6 +49,34 @@ func (m *Manager) StopWorkerPool(exchange string) {
 	}
 }
 
+// ResizeWorkerPool changes the number of workers of a running pool and
+// reports whether its size changed
+func (m *Manager) ResizeWorkerPool(exchange string, workers int) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pool, exists := m.workerPools[exchange]
+	if !exists {
+		return false
+	}
+
+	size := pool.Size()
+	pool.Resize(workers)
+	return pool.Size() != size
+}
+
+// WorkerPoolSizes returns the number of workers of every running pool
+func (m *Manager) WorkerPoolSizes() map[string]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sizes := make(map[string]int, len(m.workerPools))
+	for exchange, pool := range m.workerPools {
+		sizes[exchange] = pool.Size()
+	}
+	return sizes
+}
+
 // FanIn aggregates multiple input channels into a single output channel
 func (m *Manager) FanIn(ctx context.Context, inputs []<-chan models.PriceUpdate) <-chan models.PriceUpdate {
 	output := make(chan models.PriceUpdate)
@@ -0,0 +1,280 @@
+package concurrency
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"marketflow/internal/domain/models"
+)
+
+// OverflowPolicy decides what happens to an update when a queue is full
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for the consumer, pushing backpressure upstream
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropNewest discards the incoming update
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest evicts the oldest queued update to make room
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowCoalesce keeps only the latest pending update per exchange and symbol
+	OverflowCoalesce OverflowPolicy = "coalesce"
+)
+
+// DefaultQueueSize is the queue capacity used when none is configured
+const DefaultQueueSize = 1000
+
+// OverflowPolicies lists every supported overflow policy
+var OverflowPolicies = []OverflowPolicy{OverflowBlock, OverflowDropNewest, OverflowDropOldest, OverflowCoalesce}
+
+// IsValid reports whether the policy is supported
+func (p OverflowPolicy) IsValid() bool {
+	for _, policy := range OverflowPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+type overflowKey struct {
+	exchange string
+	symbol   string
+}
+
+// OverflowCounters counts dropped and coalesced updates per exchange and symbol
+type OverflowCounters struct {
+	mu        sync.Mutex
+	dropped   map[overflowKey]uint64
+	coalesced map[overflowKey]uint64
+}
+
+// NewOverflowCounters creates an empty set of counters
+func NewOverflowCounters() *OverflowCounters {
+	return &OverflowCounters{
+		dropped:   make(map[overflowKey]uint64),
+		coalesced: make(map[overflowKey]uint64),
+	}
+}
+
+func (c *OverflowCounters) addDropped(update models.PriceUpdate) {
+	c.mu.Lock()
+	c.dropped[overflowKey{update.Exchange, update.Symbol}]++
+	c.mu.Unlock()
+}
+
+func (c *OverflowCounters) addCoalesced(update models.PriceUpdate) {
+	c.mu.Lock()
+	c.coalesced[overflowKey{update.Exchange, update.Symbol}]++
+	c.mu.Unlock()
+}
+
+// Snapshot returns the counters sorted by exchange and symbol
+func (c *OverflowCounters) Snapshot() []models.OverflowCounter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	merged := make(map[overflowKey]*models.OverflowCounter)
+	get := func(key overflowKey) *models.OverflowCounter {
+		counter, ok := merged[key]
+		if !ok {
+			counter = &models.OverflowCounter{Exchange: key.exchange, Symbol: key.symbol}
+			merged[key] = counter
+		}
+		return counter
+	}
+	for key, n := range c.dropped {
+		get(key).Dropped = n
+	}
+	for key, n := range c.coalesced {
+		get(key).Coalesced = n
+	}
+
+	counters := make([]models.OverflowCounter, 0, len(merged))
+	for _, counter := range merged {
+		counters = append(counters, *counter)
+	}
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].Exchange != counters[j].Exchange {
+			return counters[i].Exchange < counters[j].Exchange
+		}
+		return counters[i].Symbol < counters[j].Symbol
+	})
+
+	return counters
+}
+
+// OverflowQueue is a bounded queue of price updates with a configurable overflow policy
+type OverflowQueue struct {
+	policy   OverflowPolicy
+	capacity int
+	ch       chan models.PriceUpdate
+	counters *OverflowCounters
+
+	// Coalescing state: updates wait here until the pump can forward them
+	mu      sync.Mutex
+	pending map[overflowKey]models.PriceUpdate
+	order   []overflowKey
+	notify  chan struct{}
+}
+
+// NewOverflowQueue creates a queue; unknown policies fall back to blocking
+func NewOverflowQueue(policy OverflowPolicy, capacity int, counters *OverflowCounters) *OverflowQueue {
+	if !policy.IsValid() {
+		policy = OverflowBlock
+	}
+	if capacity <= 0 {
+		capacity = DefaultQueueSize
+	}
+	if counters == nil {
+		counters = NewOverflowCounters()
+	}
+
+	// Coalesced updates wait in pending until the consumer takes them, so that
+	// it always gets the latest one; a buffer would queue them up stale instead
+	buffer := capacity
+	if policy == OverflowCoalesce {
+		buffer = 0
+	}
+
+	return &OverflowQueue{
+		policy:   policy,
+		capacity: capacity,
+		ch:       make(chan models.PriceUpdate, buffer),
+		counters: counters,
+		pending:  make(map[overflowKey]models.PriceUpdate),
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// Start runs the background pump needed by the coalesce policy
+func (q *OverflowQueue) Start(ctx context.Context) {
+	if q.policy == OverflowCoalesce {
+		go q.pump(ctx)
+	}
+}
+
+// Out returns the channel consumers read from
+func (q *OverflowQueue) Out() <-chan models.PriceUpdate {
+	return q.ch
+}
+
+// Policy returns the active overflow policy
+func (q *OverflowQueue) Policy() OverflowPolicy {
+	return q.policy
+}
+
+// Len returns the number of updates waiting to be consumed
+func (q *OverflowQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.ch) + len(q.order)
+}
+
+// Cap returns the queue capacity
+func (q *OverflowQueue) Cap() int {
+	return q.capacity
+}
+
+// Push enqueues an update according to the policy; it returns false once ctx is done
+func (q *OverflowQueue) Push(ctx context.Context, update models.PriceUpdate) bool {
+	switch q.policy {
+	case OverflowDropNewest:
+		select {
+		case q.ch <- update:
+		case <-ctx.Done():
+			return false
+		default:
+			q.counters.addDropped(update)
+		}
+		return true
+
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.ch <- update:
+				return true
+			case <-ctx.Done():
+				return false
+			default:
+			}
+
+			// Evict the oldest update and try again
+			select {
+			case evicted := <-q.ch:
+				q.counters.addDropped(evicted)
+			default:
+			}
+		}
+
+	case OverflowCoalesce:
+		key := overflowKey{update.Exchange, update.Symbol}
+
+		q.mu.Lock()
+		if _, exists := q.pending[key]; exists {
+			q.counters.addCoalesced(update)
+		} else {
+			q.order = append(q.order, key)
+		}
+		q.pending[key] = update
+		q.mu.Unlock()
+
+		select {
+		case q.notify <- struct{}{}:
+		default:
+		}
+		return ctx.Err() == nil
+
+	default:
+		select {
+		case q.ch <- update:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (q *OverflowQueue) pump(ctx context.Context) {
+	for {
+		q.mu.Lock()
+		for len(q.order) == 0 {
+			q.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.notify:
+			}
+			q.mu.Lock()
+		}
+
+		key := q.order[0]
+		q.order = q.order[1:]
+		update := q.pending[key]
+		delete(q.pending, key)
+		q.mu.Unlock()
+
+		select {
+		case q.ch <- update:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stats returns the queue state and overflow counters for a source
+func (q *OverflowQueue) Stats(source string) models.OverflowStats {
+	stats := models.OverflowStats{
+		Source:   source,
+		Policy:   string(q.policy),
+		Capacity: q.Cap(),
+		Queued:   q.Len(),
+		Counters: q.counters.Snapshot(),
+	}
+	for _, counter := range stats.Counters {
+		stats.Dropped += counter.Dropped
+		stats.Coalesced += counter.Coalesced
+	}
+	return stats
+}
@@ -0,0 +1,95 @@
+package concurrency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+func tick(symbol string, price float64) models.PriceUpdate {
+	return models.PriceUpdate{Exchange: "exchange1", Symbol: symbol, Price: price}
+}
+
+func TestOverflowQueueDropOldest(t *testing.T) {
+	counters := NewOverflowCounters()
+	queue := NewOverflowQueue(OverflowDropOldest, 2, counters)
+	queue.Start(context.Background())
+
+	for i, symbol := range []string{"BTCUSDT", "BTCUSDT", "ETHUSDT", "BTCUSDT", "ETHUSDT"} {
+		if !queue.Push(context.Background(), tick(symbol, float64(i))) {
+			t.Fatalf("push %d refused", i)
+		}
+	}
+
+	stats := queue.Stats("test")
+	if stats.Dropped != 3 || stats.Queued != 2 || stats.Capacity != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	want := []models.OverflowCounter{
+		{Exchange: "exchange1", Symbol: "BTCUSDT", Dropped: 2},
+		{Exchange: "exchange1", Symbol: "ETHUSDT", Dropped: 1},
+	}
+	if len(stats.Counters) != len(want) {
+		t.Fatalf("counters %+v, want %+v", stats.Counters, want)
+	}
+	for i := range want {
+		if stats.Counters[i] != want[i] {
+			t.Fatalf("counters %+v, want %+v", stats.Counters, want)
+		}
+	}
+
+	// The newest updates survive, in order
+	for _, price := range []float64{3, 4} {
+		if got := <-queue.Out(); got.Price != price {
+			t.Fatalf("got price %v, want %v", got.Price, price)
+		}
+	}
+}
+
+func TestOverflowQueueCoalesceKeepsTheLatestUpdate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	counters := NewOverflowCounters()
+	queue := NewOverflowQueue(OverflowCoalesce, 1000, counters)
+
+	// Nothing is consumed while the updates arrive
+	updates := []models.PriceUpdate{
+		tick("BTCUSDT", 1), tick("ETHUSDT", 10), tick("BTCUSDT", 2), tick("BTCUSDT", 3), tick("ETHUSDT", 11),
+	}
+	for _, update := range updates {
+		queue.Push(ctx, update)
+	}
+	if got := queue.Len(); got != 2 {
+		t.Fatalf("%d updates pending, want one per symbol", got)
+	}
+	queue.Start(ctx)
+
+	for _, want := range []models.PriceUpdate{tick("BTCUSDT", 3), tick("ETHUSDT", 11)} {
+		select {
+		case got := <-queue.Out():
+			if got != want {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no update forwarded")
+		}
+	}
+
+	stats := queue.Stats("test")
+	if stats.Coalesced != 3 || stats.Dropped != 0 || stats.Capacity != 1000 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// The pump does not run ahead of the consumer into a buffer
+	queue.Push(ctx, tick("BTCUSDT", 4))
+	queue.Push(ctx, tick("BTCUSDT", 5))
+	time.Sleep(10 * time.Millisecond)
+	queue.Push(ctx, tick("BTCUSDT", 6))
+	queue.Push(ctx, tick("BTCUSDT", 7))
+	if got := queue.Len(); got > 1 {
+		t.Fatalf("%d updates queued for one symbol", got)
+	}
+}
@@ -14,10 +14,24 @@ type WorkerPool struct {
 	logger  *slog.Logger
 	done    chan struct{}
 	wg      sync.WaitGroup
+
+	// Set by Start so the pool can be resized while running
+	mu       sync.Mutex
+	ctx      context.Context
+	inputCh  <-chan models.PriceUpdate
+	outputCh chan<- models.PriceUpdate
+	stops    []chan struct{}
+	nextID   int
+	running  int  // workers that have not exited yet
+	stopped  bool // set once no worker may be added: after Stop or when every worker exited
 }
 
 // NewWorkerPool creates a new worker pool
 func NewWorkerPool(workers int, logger *slog.Logger) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+
 	return &WorkerPool{
 		workers: workers,
 		logger:  logger,
@@ -27,22 +41,93 @@ func NewWorkerPool(workers int, logger *slog.Logger) *WorkerPool {
 
 // Start starts the worker pool
 func (wp *WorkerPool) Start(ctx context.Context, inputCh <-chan models.PriceUpdate, outputCh chan<- models.PriceUpdate) {
+	wp.mu.Lock()
+	if wp.stopped {
+		wp.mu.Unlock()
+		return
+	}
+	wp.ctx = ctx
+	wp.inputCh = inputCh
+	wp.outputCh = outputCh
 	for i := 0; i < wp.workers; i++ {
-		wp.wg.Add(1)
-		go wp.worker(ctx, i, inputCh, outputCh)
+		wp.spawn()
 	}
+	wp.mu.Unlock()
 
 	wp.wg.Wait()
 }
 
 // Stop stops the worker pool
 func (wp *WorkerPool) Stop() {
-	close(wp.done)
+	wp.mu.Lock()
+	select {
+	case <-wp.done: // already stopped
+	default:
+		close(wp.done)
+	}
+	wp.stopped = true
+	wp.mu.Unlock()
+
 	wp.wg.Wait()
 }
 
-func (wp *WorkerPool) worker(ctx context.Context, id int, inputCh <-chan models.PriceUpdate, outputCh chan<- models.PriceUpdate) {
-	defer wp.wg.Done()
+// Resize grows or shrinks the number of running workers
+func (wp *WorkerPool) Resize(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.stopped || (wp.ctx != nil && wp.ctx.Err() != nil) {
+		wp.logger.Warn("Worker pool stopped, not resizing", "workers", workers)
+		return
+	}
+
+	if workers == wp.workers {
+		return
+	}
+	wp.workers = workers
+	if wp.ctx == nil {
+		// Not started yet; Start will use the new size
+		return
+	}
+
+	for len(wp.stops) < workers {
+		wp.spawn()
+	}
+	for len(wp.stops) > workers {
+		last := len(wp.stops) - 1
+		close(wp.stops[last])
+		wp.stops = wp.stops[:last]
+	}
+
+	wp.logger.Info("Worker pool resized", "workers", workers)
+}
+
+// Size returns the configured number of workers
+func (wp *WorkerPool) Size() int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	return wp.workers
+}
+
+// spawn starts one more worker; callers must hold wp.mu. While the pool is
+// not stopped at least one worker is running, so the WaitGroup counter is
+// above zero and Add cannot race with a Wait that is about to return.
+func (wp *WorkerPool) spawn() {
+	stop := make(chan struct{})
+	wp.stops = append(wp.stops, stop)
+
+	wp.running++
+	wp.wg.Add(1)
+	go wp.worker(wp.ctx, wp.nextID, stop, wp.inputCh, wp.outputCh)
+	wp.nextID++
+}
+
+func (wp *WorkerPool) worker(ctx context.Context, id int, stop <-chan struct{}, inputCh <-chan models.PriceUpdate, outputCh chan<- models.PriceUpdate) {
+	defer wp.exit()
 
 	wp.logger.Debug("Worker started", "worker_id", id)
 	defer wp.logger.Debug("Worker stopped", "worker_id", id)
@@ -53,6 +138,8 @@ func (wp *WorkerPool) worker(ctx context.Context, id int, inputCh <-chan models.
 			return
 		case <-wp.done:
 			return
+		case <-stop:
+			return
 		case update, ok := <-inputCh:
 			if !ok {
 				return
@@ -72,6 +159,19 @@ func (wp *WorkerPool) worker(ctx context.Context, id int, inputCh <-chan models.
 	}
 }
 
+// exit records that a worker returned; the last one marks the pool stopped
+// before the WaitGroup reaches zero
+func (wp *WorkerPool) exit() {
+	wp.mu.Lock()
+	wp.running--
+	if wp.running == 0 {
+		wp.stopped = true
+	}
+	wp.mu.Unlock()
+
+	wp.wg.Done()
+}
+
 func (wp *WorkerPool) processUpdate(update models.PriceUpdate) models.PriceUpdate {
 	// Add any processing logic here (validation, transformation, etc.)
 	return update
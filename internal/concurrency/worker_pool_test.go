@@ -0,0 +1,60 @@
+package concurrency
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"marketflow/internal/domain/models"
+)
+
+func TestWorkerPoolResizeWhileStopping(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for i := 0; i < 50; i++ {
+		pool := NewWorkerPool(2, logger)
+		input := make(chan models.PriceUpdate)
+		output := make(chan models.PriceUpdate, 1)
+
+		started := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			close(started)
+			pool.Start(context.Background(), input, output)
+		}()
+		<-started
+		go func() {
+			defer wg.Done()
+			for size := 1; size <= 8; size++ {
+				pool.Resize(size)
+			}
+		}()
+		pool.Stop()
+		wg.Wait()
+
+		pool.Resize(20)
+		if got := pool.Size(); got == 20 {
+			t.Fatalf("Resize after Stop changed the size to %d", got)
+		}
+	}
+}
+
+func TestWorkerPoolStopsWhenInputCloses(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pool := NewWorkerPool(3, logger)
+	input := make(chan models.PriceUpdate)
+	output := make(chan models.PriceUpdate, 1)
+
+	close(input)
+	pool.Start(context.Background(), input, output)
+
+	pool.Resize(5)
+	if got := pool.Size(); got != 3 {
+		t.Fatalf("Resize after every worker exited changed the size to %d", got)
+	}
+	pool.Stop()
+}
@@ -2,70 +2,360 @@ package config
 
 import (
 	"encoding/json"
-	"os"
+	"fmt"
+	"log/slog"
+	"time"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Database  DatabaseConfig  `json:"database"`
-	Cache     CacheConfig     `json:"cache"`
-	Exchanges ExchangesConfig `json:"exchanges"`
-	Server    ServerConfig    `json:"server"`
+	Server     ServerConfig     `json:"server" yaml:"server"`
+	Logging    LoggingConfig    `json:"logging" yaml:"logging"`
+	Database   DatabaseConfig   `json:"database" yaml:"database"`
+	Exchanges  ExchangesConfig  `json:"exchanges" yaml:"exchanges"`
+	Processing ProcessingConfig `json:"processing" yaml:"processing"`
+	Symbols    []SymbolConfig   `json:"symbols" yaml:"symbols"`
+
+	// File is the configuration file the values were read from, if any
+	File string `json:"-" yaml:"-"`
 }
 
-// DatabaseConfig represents PostgreSQL configuration
+// DatabaseConfig groups the storage and cache connections
 type DatabaseConfig struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	User     string `json:"user"`
-	Password string `json:"password"`
-	Database string `json:"database"`
-	SSLMode  string `json:"ssl_mode"`
+	Postgres PostgresConfig `json:"postgres" yaml:"postgres"`
+	Redis    RedisConfig    `json:"redis" yaml:"redis"`
+	Spool    SpoolConfig    `json:"spool" yaml:"spool"`
+}
+
+// SpoolConfig represents the disk spool that keeps batches the database rejected until it recovers
+type SpoolConfig struct {
+	Enabled        bool     `json:"enabled" yaml:"enabled"`
+	Dir            string   `json:"dir" yaml:"dir"`
+	MaxSizeMB      int      `json:"max_size_mb" yaml:"max_size_mb"` // batches are dropped once the spool reaches this size
+	ReplayInterval Duration `json:"replay_interval" yaml:"replay_interval"`
 }
 
-// CacheConfig represents Redis configuration
-type CacheConfig struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	Password string `json:"password"`
-	Database int    `json:"database"`
+// PostgresConfig represents PostgreSQL configuration
+type PostgresConfig struct {
+	Host        string `json:"host" yaml:"host"`
+	Port        int    `json:"port" yaml:"port"`
+	User        string `json:"user" yaml:"user"`
+	Password    string `json:"password" yaml:"password"`
+	Database    string `json:"database" yaml:"database"`
+	SSLMode     string `json:"sslmode" yaml:"sslmode"`
+	AutoMigrate bool   `json:"auto_migrate" yaml:"auto_migrate"` // apply pending schema migrations on startup
+}
+
+// RedisConfig represents Redis configuration
+type RedisConfig struct {
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	Password string `json:"password" yaml:"password"`
+	Database int    `json:"db" yaml:"db"`
 }
 
 // ExchangesConfig represents exchange configuration
 type ExchangesConfig struct {
-	Exchange1 ExchangeConfig `json:"exchange1"`
-	Exchange2 ExchangeConfig `json:"exchange2"`
-	Exchange3 ExchangeConfig `json:"exchange3"`
-	Test      ExchangeConfig `json:"test"`
+	Live         []ExchangeConfig   `json:"live" yaml:"live"`
+	Test         ExchangeConfig     `json:"test" yaml:"test"`
+	Reconnect    ReconnectConfig    `json:"reconnect" yaml:"reconnect"`
+	StaleTimeout Duration           `json:"stale_timeout" yaml:"stale_timeout"` // silence after which a feed is reconnected
+	Backpressure BackpressureConfig `json:"backpressure" yaml:"backpressure"`
 }
 
 // ExchangeConfig represents individual exchange configuration
 type ExchangeConfig struct {
-	Host string `json:"host"`
-	Port int    `json:"port"`
+	Name     string   `json:"name" yaml:"name"`
+	Host     string   `json:"host" yaml:"host"`
+	Port     int      `json:"port" yaml:"port"`
+	Enabled  *bool    `json:"enabled,omitempty" yaml:"enabled"`   // defaults to true
+	Symbols  []string `json:"symbols,omitempty" yaml:"symbols"`   // empty accepts every symbol
+	Protocol string   `json:"protocol,omitempty" yaml:"protocol"` // defaults to "json"
+}
+
+// IsEnabled reports whether the exchange should be connected
+func (c ExchangeConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// ReconnectConfig represents the reconnect backoff policy for live exchanges
+type ReconnectConfig struct {
+	InitialDelay Duration `json:"initial_delay" yaml:"initial_delay"`
+	MaxDelay     Duration `json:"max_delay" yaml:"max_delay"`
+	Multiplier   float64  `json:"multiplier" yaml:"multiplier"`
+	Jitter       float64  `json:"jitter" yaml:"jitter"`
+	MaxAttempts  int      `json:"max_attempts" yaml:"max_attempts"` // 0 retries forever
+}
+
+// BackpressureConfig represents the overflow policy of each data source
+type BackpressureConfig struct {
+	Live OverflowConfig `json:"live" yaml:"live"`
+	Test OverflowConfig `json:"test" yaml:"test"`
+}
+
+// OverflowPolicies lists the accepted exchanges.backpressure.*.policy values
+var OverflowPolicies = []string{"block", "drop_newest", "drop_oldest", "coalesce"}
+
+// OverflowConfig represents the queue between a data source and the workers
+type OverflowConfig struct {
+	Policy     string `json:"policy" yaml:"policy"` // block, drop_newest, drop_oldest or coalesce
+	BufferSize int    `json:"buffer_size" yaml:"buffer_size"`
+}
+
+// ProcessingConfig represents data processing configuration
+type ProcessingConfig struct {
+	WorkersPerExchange  int             `json:"workers_per_exchange" yaml:"workers_per_exchange"`
+	BatchSize           int             `json:"batch_size" yaml:"batch_size"`
+	AggregationInterval Duration        `json:"aggregation_interval" yaml:"aggregation_interval"`
+	CandleIntervals     []Duration      `json:"candle_intervals" yaml:"candle_intervals"` // OHLC candles are built at each interval
+	Windowing           WindowingConfig `json:"windowing" yaml:"windowing"`
+	Failover            FailoverConfig  `json:"failover" yaml:"failover"`
+}
+
+// TimeSources lists the accepted processing.windowing.time_source values
+var TimeSources = []string{"received", "event"}
+
+// LatePolicies lists the accepted processing.windowing.late_policy values
+var LatePolicies = []string{"drop", "amend", "correct"}
+
+// WindowingConfig decides which clock places updates in aggregation windows and what happens to late updates
+type WindowingConfig struct {
+	TimeSource      string   `json:"time_source" yaml:"time_source"`           // received or event
+	AllowedLateness Duration `json:"allowed_lateness" yaml:"allowed_lateness"` // how long after a window closes it is aggregated
+	LatePolicy      string   `json:"late_policy" yaml:"late_policy"`           // drop, amend or correct
+}
+
+// CandleDurations returns the candle intervals as time.Durations
+func (c ProcessingConfig) CandleDurations() []time.Duration {
+	intervals := make([]time.Duration, 0, len(c.CandleIntervals))
+	for _, interval := range c.CandleIntervals {
+		intervals = append(intervals, interval.Std())
+	}
+	return intervals
+}
+
+// minHistoryRetention is the shortest time the cache keeps price history
+const minHistoryRetention = 2 * time.Minute
+
+// HistoryRetention returns how long the cache keeps the updates of every
+// symbol for window recovery: the window in progress and the one before it,
+// plus the allowed lateness by which an update may precede its window
+func (c ProcessingConfig) HistoryRetention() time.Duration {
+	retention := 2 * c.AggregationInterval.Std()
+	if lateness := c.Windowing.AllowedLateness.Std(); lateness > 0 {
+		retention += lateness
+	}
+	if retention < minHistoryRetention {
+		retention = minHistoryRetention
+	}
+	return retention
+}
+
+// FailoverConfig represents automatic failover from live to test data
+type FailoverConfig struct {
+	Enabled       bool     `json:"enabled" yaml:"enabled"`
+	FailoverAfter Duration `json:"failover_after" yaml:"failover_after"` // how long live must be down before switching
+	FailbackAfter Duration `json:"failback_after" yaml:"failback_after"` // how long live must be healthy before switching back
+	CheckInterval Duration `json:"check_interval" yaml:"check_interval"`
+}
+
+// SymbolConfig describes a tracked symbol; in files it may also be written as just its name
+type SymbolConfig struct {
+	Name      string `json:"name" yaml:"name"`
+	Base      string `json:"base,omitempty" yaml:"base"`           // derived from the name when empty
+	Quote     string `json:"quote,omitempty" yaml:"quote"`         // derived from the name when empty
+	Precision *int   `json:"precision,omitempty" yaml:"precision"` // defaults to 2 decimal places
+}
+
+// decodeScalar accepts the short form "BTCUSDT"
+func (c *SymbolConfig) decodeScalar(raw string) error {
+	*c = SymbolConfig{Name: raw}
+	return nil
 }
 
 // ServerConfig represents server configuration
 type ServerConfig struct {
-	Port int `json:"port"`
+	Port      int             `json:"port" yaml:"port"`
+	Health    HealthConfig    `json:"health" yaml:"health"`
+	Stream    StreamConfig    `json:"stream" yaml:"stream"`
+	WebSocket WebSocketConfig `json:"websocket" yaml:"websocket"`
+}
+
+// StreamConfig represents configuration of the live price stream
+type StreamConfig struct {
+	ReplayBuffer int      `json:"replay_buffer" yaml:"replay_buffer"` // recent updates kept for Last-Event-ID resume
+	ClientBuffer int      `json:"client_buffer" yaml:"client_buffer"` // updates queued per client before it is evicted
+	Heartbeat    Duration `json:"heartbeat" yaml:"heartbeat"`         // interval of keep-alive comments
+}
+
+// WebSocketConfig represents configuration of the WebSocket subscription API
+type WebSocketConfig struct {
+	SendQueue    int      `json:"send_queue" yaml:"send_queue"`       // messages queued per connection before it is closed
+	PingInterval Duration `json:"ping_interval" yaml:"ping_interval"` // a connection is closed after two intervals without a pong
+
+	// AllowedOrigins lists the origins of other sites whose pages may connect,
+	// e.g. https://dashboard.example.com, or "*" for any
+	AllowedOrigins []string `json:"allowed_origins" yaml:"allowed_origins"`
+}
+
+// HealthDependencies are the dependencies reported by the health endpoints
+var HealthDependencies = []string{"postgres", "redis", "exchange"}
+
+// HealthConfig represents health check configuration
+type HealthConfig struct {
+	// Critical lists the dependencies the service is not ready without
+	Critical []string `json:"critical" yaml:"critical"`
 }
 
-// Load loads configuration from file
-func Load() (*Config, error) {
-	configFile := "configs/config.json"
-	if envFile := os.Getenv("CONFIG_FILE"); envFile != "" {
-		configFile = envFile
+// LoggingConfig represents logging configuration
+type LoggingConfig struct {
+	Level string `json:"level" yaml:"level"` // debug, info, warn or error
+}
+
+// SlogLevel parses the configured level
+func (c LoggingConfig) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(c.Level))
+	return level, err
+}
+
+// Defaults returns the configuration used for every key that is not set elsewhere
+func Defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port: 8080,
+			Health: HealthConfig{
+				Critical: []string{"postgres", "exchange"},
+			},
+			Stream: StreamConfig{
+				ReplayBuffer: 1000,
+				ClientBuffer: 256,
+				Heartbeat:    Duration(15 * time.Second),
+			},
+			WebSocket: WebSocketConfig{
+				SendQueue:    256,
+				PingInterval: Duration(30 * time.Second),
+			},
+		},
+		Logging: LoggingConfig{
+			Level: "info",
+		},
+		Database: DatabaseConfig{
+			Postgres: PostgresConfig{
+				Host:        "localhost",
+				Port:        5433,
+				User:        "marketflow",
+				Password:    "password",
+				Database:    "marketflow",
+				SSLMode:     "disable",
+				AutoMigrate: true,
+			},
+			Spool: SpoolConfig{
+				Enabled:        true,
+				Dir:            "spool",
+				MaxSizeMB:      256,
+				ReplayInterval: Duration(5 * time.Second),
+			},
+			Redis: RedisConfig{
+				Host: "localhost",
+				Port: 6379,
+			},
+		},
+		Exchanges: ExchangesConfig{
+			Live: []ExchangeConfig{
+				{Name: "exchange1", Host: "127.0.0.1", Port: 40101},
+				{Name: "exchange2", Host: "127.0.0.1", Port: 40102},
+				{Name: "exchange3", Host: "127.0.0.1", Port: 40103},
+			},
+			Test: ExchangeConfig{
+				Host: "127.0.0.1",
+				Port: 50000,
+			},
+			Reconnect: ReconnectConfig{
+				InitialDelay: Duration(500 * time.Millisecond),
+				MaxDelay:     Duration(30 * time.Second),
+				Multiplier:   2,
+				Jitter:       0.2,
+			},
+			StaleTimeout: Duration(15 * time.Second),
+			Backpressure: BackpressureConfig{
+				Live: OverflowConfig{Policy: "block", BufferSize: 1000},
+				Test: OverflowConfig{Policy: "block", BufferSize: 1000},
+			},
+		},
+		Processing: ProcessingConfig{
+			WorkersPerExchange:  5,
+			BatchSize:           100,
+			AggregationInterval: Duration(time.Minute),
+			CandleIntervals: []Duration{
+				Duration(time.Second),
+				Duration(5 * time.Second),
+				Duration(time.Minute),
+				Duration(5 * time.Minute),
+				Duration(time.Hour),
+			},
+			Windowing: WindowingConfig{
+				TimeSource:      "received",
+				AllowedLateness: Duration(time.Second),
+				LatePolicy:      "drop",
+			},
+			Failover: FailoverConfig{
+				FailoverAfter: Duration(15 * time.Second),
+				FailbackAfter: Duration(10 * time.Second),
+				CheckInterval: Duration(time.Second),
+			},
+		},
+		Symbols: []SymbolConfig{
+			{Name: "BTCUSDT", Base: "BTC", Quote: "USDT", Precision: intPtr(2)},
+			{Name: "DOGEUSDT", Base: "DOGE", Quote: "USDT", Precision: intPtr(5)},
+			{Name: "TONUSDT", Base: "TON", Quote: "USDT", Precision: intPtr(4)},
+			{Name: "SOLUSDT", Base: "SOL", Quote: "USDT", Precision: intPtr(2)},
+			{Name: "ETHUSDT", Base: "ETH", Quote: "USDT", Precision: intPtr(2)},
+		},
 	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
 
-	data, err := os.ReadFile(configFile)
-	if err != nil {
-		return nil, err
+// Duration is a time.Duration that is written as a string ("5s", "1m") in configuration files
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string or a number of nanoseconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, err
+	switch v := value.(type) {
+	case float64:
+		*d = Duration(time.Duration(v))
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", v, err)
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("invalid duration %s", string(data))
 	}
 
-	return &config, nil
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Std returns the duration as a time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// String returns the duration formatted like "1m30s"
+func (d Duration) String() string {
+	return time.Duration(d).String()
 }
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProcessingConfigHistoryRetention(t *testing.T) {
+	tests := []struct {
+		interval, lateness, want time.Duration
+	}{
+		{time.Minute, time.Second, 2*time.Minute + time.Second},
+		{5 * time.Minute, 10 * time.Second, 10*time.Minute + 10*time.Second},
+		{10 * time.Second, time.Second, minHistoryRetention},
+		{time.Minute, -time.Second, 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		cfg := ProcessingConfig{
+			AggregationInterval: Duration(tt.interval),
+			Windowing:           WindowingConfig{AllowedLateness: Duration(tt.lateness)},
+		}
+		if got := cfg.HistoryRetention(); got != tt.want {
+			t.Errorf("interval %s, lateness %s: got %s, want %s", tt.interval, tt.lateness, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix prefixes the environment variables that override configuration keys,
+// e.g. MARKETFLOW_DATABASE_POSTGRES_HOST overrides database.postgres.host
+const EnvPrefix = "MARKETFLOW_"
+
+// DefaultPaths are searched in order when no configuration file is given
+var DefaultPaths = []string{"config.yaml", "configs/config.yaml", "configs/config.json"}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// scalarDecoder is implemented by sections that may also be written as a single value
+type scalarDecoder interface {
+	decodeScalar(raw string) error
+}
+
+var scalarDecoderType = reflect.TypeOf((*scalarDecoder)(nil)).Elem()
+
+// isSection reports whether values of t can only be set key by key
+func isSection(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != durationType && !reflect.PointerTo(t).Implements(scalarDecoderType)
+}
+
+// Options controls where configuration is loaded from
+type Options struct {
+	Path      string   // configuration file; empty falls back to CONFIG_FILE, then DefaultPaths
+	Overrides []string // "key.path=value" pairs, usually from --set flags
+}
+
+// Error lists every problem found while loading configuration
+type Error struct {
+	File     string
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	source := e.File
+	if source == "" {
+		source = "configuration"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d problem(s)", source, len(e.Problems))
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem)
+	}
+	return b.String()
+}
+
+func (e *Error) add(key, format string, args ...interface{}) {
+	e.Problems = append(e.Problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+// Load builds the configuration from, in increasing order of precedence:
+// built-in defaults, a YAML or JSON file, MARKETFLOW_* environment variables
+// and command-line overrides, then validates the result. Unknown keys and
+// invalid values are reported together in an *Error
+func Load(opts Options) (*Config, error) {
+	cfg := Defaults()
+	problems := &Error{}
+	root := reflect.ValueOf(cfg).Elem()
+
+	if path := resolvePath(opts.Path); path != "" {
+		cfg.File = path
+		problems.File = path
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		// YAML is a superset of JSON, so both formats share one decoder
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if len(doc.Content) > 0 {
+			decodeNode(doc.Content[0], root, "", problems)
+		}
+	}
+
+	applyEnv(root, nil, os.LookupEnv, problems)
+
+	for _, override := range opts.Overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			problems.add(override, "override must have the form key=value")
+			continue
+		}
+		setPath(root, key, value, problems)
+	}
+
+	cfg.validate(problems)
+
+	if len(problems.Problems) > 0 {
+		return nil, problems
+	}
+	return cfg, nil
+}
+
+func resolvePath(path string) string {
+	if path != "" {
+		return path
+	}
+	if envFile := os.Getenv("CONFIG_FILE"); envFile != "" {
+		return envFile
+	}
+	for _, candidate := range DefaultPaths {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// decodeNode writes a YAML node onto v, keeping values the node does not mention
+func decodeNode(node *yaml.Node, v reflect.Value, key string, problems *Error) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct && v.Type() != durationType:
+		if node.Kind == yaml.ScalarNode && v.Addr().Type().Implements(scalarDecoderType) {
+			if err := setScalar(v, node.Value); err != nil {
+				problems.add(displayKey(key), "%v", err)
+			}
+			return
+		}
+		if node.Kind != yaml.MappingNode {
+			problems.add(displayKey(key), "expected a mapping")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name := node.Content[i].Value
+			field, ok := fieldByKey(v, name)
+			if !ok {
+				problems.add(joinKey(key, name), "unknown key (line %d)", node.Content[i].Line)
+				continue
+			}
+			decodeNode(node.Content[i+1], field, joinKey(key, name), problems)
+		}
+
+	case v.Kind() == reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			problems.add(displayKey(key), "expected a list")
+			return
+		}
+		slice := reflect.MakeSlice(v.Type(), len(node.Content), len(node.Content))
+		for i, item := range node.Content {
+			decodeNode(item, slice.Index(i), fmt.Sprintf("%s[%d]", key, i), problems)
+		}
+		v.Set(slice)
+
+	default:
+		if node.Kind != yaml.ScalarNode {
+			problems.add(displayKey(key), "expected a single value")
+			return
+		}
+		if err := setScalar(v, node.Value); err != nil {
+			problems.add(displayKey(key), "%v", err)
+		}
+	}
+}
+
+// applyEnv overrides every scalar key that has a matching MARKETFLOW_* variable
+func applyEnv(v reflect.Value, path []string, lookup func(string) (string, bool), problems *Error) {
+	for i := 0; i < v.NumField(); i++ {
+		name := keyOf(v.Type().Field(i))
+		if name == "" {
+			continue
+		}
+
+		fieldPath := append(append([]string{}, path...), name)
+		field := v.Field(i)
+
+		switch {
+		case field.Kind() == reflect.Struct && field.Type() != durationType:
+			applyEnv(field, fieldPath, lookup, problems)
+
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Struct:
+			if !isSection(field.Type()) {
+				// Lists of short-form entries can also be replaced as a whole, e.g. MARKETFLOW_SYMBOLS=BTCUSDT,ETHUSDT
+				env := EnvPrefix + strings.ToUpper(strings.Join(fieldPath, "_"))
+				if value, ok := lookup(env); ok {
+					if err := setScalar(field, value); err != nil {
+						problems.add(fmt.Sprintf("%s (%s)", strings.Join(fieldPath, "."), env), "%v", err)
+					}
+					continue
+				}
+			}
+			for j := 0; j < field.Len(); j++ {
+				applyEnv(field.Index(j), append(fieldPath, strconv.Itoa(j)), lookup, problems)
+			}
+
+		default:
+			env := EnvPrefix + strings.ToUpper(strings.Join(fieldPath, "_"))
+			value, ok := lookup(env)
+			if !ok {
+				continue
+			}
+			if err := setScalar(field, value); err != nil {
+				problems.add(fmt.Sprintf("%s (%s)", strings.Join(fieldPath, "."), env), "%v", err)
+			}
+		}
+	}
+}
+
+// setPath sets a single value addressed by a dotted key such as "exchanges.live.0.port"
+func setPath(v reflect.Value, key, raw string, problems *Error) {
+	for _, segment := range strings.Split(key, ".") {
+		switch {
+		case v.Kind() == reflect.Struct && v.Type() != durationType:
+			field, ok := fieldByKey(v, segment)
+			if !ok {
+				problems.add(key, "unknown key")
+				return
+			}
+			v = field
+
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= v.Len() {
+				problems.add(key, "no list entry %q", segment)
+				return
+			}
+			v = v.Index(index)
+
+		default:
+			problems.add(key, "unknown key")
+			return
+		}
+	}
+
+	if isSection(v.Type()) {
+		problems.add(key, "is a section; set one of its keys instead")
+		return
+	}
+	if err := setScalar(v, raw); err != nil {
+		problems.add(key, "%v", err)
+	}
+}
+
+// setScalar parses raw into v according to v's type
+func setScalar(v reflect.Value, raw string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(scalarDecoderType) {
+		return v.Addr().Interface().(scalarDecoder).decodeScalar(raw)
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setScalar(elem.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(elem)
+
+	case reflect.Slice:
+		// Lists given as a single value are comma-separated, e.g. MARKETFLOW_SYMBOLS=BTCUSDT,ETHUSDT
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setScalar(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+
+	default:
+		return fmt.Errorf("unsupported value type %s", v.Type())
+	}
+
+	return nil
+}
+
+func fieldByKey(v reflect.Value, key string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if name := keyOf(v.Type().Field(i)); name != "" && name == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// keyOf returns the configuration key of a struct field, or "" if it is not configurable
+func keyOf(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" || !field.IsExported() {
+		return ""
+	}
+	return name
+}
+
+func joinKey(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func displayKey(key string) string {
+	if key == "" {
+		return "(root)"
+	}
+	return key
+}
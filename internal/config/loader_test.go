@@ -0,0 +1,175 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// withoutDefaultFile keeps Load from picking up a configuration file of the environment
+func withoutDefaultFile(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	paths := DefaultPaths
+	DefaultPaths = nil
+	t.Cleanup(func() { DefaultPaths = paths })
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	yamlFile := "server:\n  port: 9000\ndatabase:\n  postgres:\n    host: db.internal\nprocessing:\n  batch_size: 50\n"
+	jsonFile := `{"server": {"port": 9000}, "database": {"postgres": {"host": "db.internal"}}, "processing": {"batch_size": 50}}`
+
+	tests := []struct {
+		name      string
+		file      string // file name and content, empty for none
+		content   string
+		env       map[string]string
+		overrides []string
+		port      int
+		host      string
+		batchSize int
+		interval  time.Duration
+	}{
+		{name: "defaults", port: 8080, host: "localhost", batchSize: 100, interval: time.Minute},
+		{name: "yaml file", file: "config.yaml", content: yamlFile, port: 9000, host: "db.internal", batchSize: 50, interval: time.Minute},
+		{name: "json file", file: "config.json", content: jsonFile, port: 9000, host: "db.internal", batchSize: 50, interval: time.Minute},
+		{
+			name: "env over file", file: "config.yaml", content: yamlFile,
+			env:  map[string]string{"MARKETFLOW_SERVER_PORT": "9100", "MARKETFLOW_PROCESSING_AGGREGATION_INTERVAL": "5m"},
+			port: 9100, host: "db.internal", batchSize: 50, interval: 5 * time.Minute,
+		},
+		{
+			name: "set over env", file: "config.yaml", content: yamlFile,
+			env:       map[string]string{"MARKETFLOW_SERVER_PORT": "9100", "MARKETFLOW_DATABASE_POSTGRES_HOST": "env.internal"},
+			overrides: []string{"server.port=9200", "processing.batch_size=10"},
+			port:      9200, host: "env.internal", batchSize: 10, interval: time.Minute,
+		},
+		{
+			name: "set without file", overrides: []string{"database.postgres.host=flag.internal", "processing.aggregation_interval=30s"},
+			port: 8080, host: "flag.internal", batchSize: 100, interval: 30 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withoutDefaultFile(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			opts := Options{Overrides: tt.overrides}
+			if tt.file != "" {
+				opts.Path = writeConfig(t, tt.file, tt.content)
+			}
+
+			cfg, err := Load(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Server.Port != tt.port || cfg.Database.Postgres.Host != tt.host ||
+				cfg.Processing.BatchSize != tt.batchSize || cfg.Processing.AggregationInterval.Std() != tt.interval {
+				t.Fatalf("port %d, host %s, batch size %d, interval %s; want %d, %s, %d, %s",
+					cfg.Server.Port, cfg.Database.Postgres.Host, cfg.Processing.BatchSize, cfg.Processing.AggregationInterval,
+					tt.port, tt.host, tt.batchSize, tt.interval)
+			}
+
+			// Keys no source sets keep their defaults
+			if cfg.Database.Redis.Port != 6379 || len(cfg.Exchanges.Live) != 3 {
+				t.Fatalf("defaults lost: redis port %d, %d live exchanges", cfg.Database.Redis.Port, len(cfg.Exchanges.Live))
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		overrides []string
+		want      []string // one problem per entry, matched by prefix
+	}{
+		{
+			name:    "top-level key in the file",
+			content: "server:\n  port: 9000\nservers:\n  port: 9001\n",
+			want:    []string{"servers: unknown key (line 3)"},
+		},
+		{
+			name:    "nested keys in the file",
+			content: "database:\n  postgres:\n    hots: db\n  redis:\n    prot: 1\n",
+			want:    []string{"database.postgres.hots: unknown key", "database.redis.prot: unknown key"},
+		},
+		{
+			name:    "key of a list entry",
+			content: "exchanges:\n  live:\n    - name: exchange1\n      host: 127.0.0.1\n      port: 40101\n      adress: x\n",
+			want:    []string{"exchanges.live[0].adress: unknown key"},
+		},
+		{
+			name:      "override key",
+			overrides: []string{"server.prot=9000"},
+			want:      []string{"server.prot: unknown key"},
+		},
+		{
+			name:      "override past a value",
+			overrides: []string{"server.port.number=9000"},
+			want:      []string{"server.port.number: unknown key"},
+		},
+		{
+			name:      "override of a missing list entry",
+			overrides: []string{"exchanges.live.7.port=1"},
+			want:      []string{`exchanges.live.7.port: no list entry "7"`},
+		},
+		{
+			name:      "override of a section",
+			overrides: []string{"database.postgres=db"},
+			want:      []string{"database.postgres: is a section"},
+		},
+		{
+			name:      "override without a value",
+			overrides: []string{"server.port"},
+			want:      []string{"server.port: override must have the form key=value"},
+		},
+		{
+			name:      "every problem at once",
+			content:   "loging:\n  level: debug\n",
+			overrides: []string{"server.prot=1"},
+			want:      []string{"loging: unknown key", "server.prot: unknown key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withoutDefaultFile(t)
+
+			opts := Options{Overrides: tt.overrides}
+			if tt.content != "" {
+				opts.Path = writeConfig(t, "config.yaml", tt.content)
+			}
+
+			_, err := Load(opts)
+			var cfgErr *Error
+			if !errors.As(err, &cfgErr) {
+				t.Fatalf("got %v, want an *Error", err)
+			}
+			if len(cfgErr.Problems) != len(tt.want) {
+				t.Fatalf("problems %q, want %q", cfgErr.Problems, tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(cfgErr.Problems[i], want) {
+					t.Errorf("problem %q, want %q", cfgErr.Problems[i], want)
+				}
+			}
+		})
+	}
+}
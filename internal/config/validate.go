@@ -0,0 +1,251 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// sslModes lists the sslmode values understood by lib/pq
+var sslModes = map[string]bool{
+	"disable": true, "require": true, "verify-ca": true, "verify-full": true,
+}
+
+// maxSymbolPrecision is the largest number of decimal places a symbol may set
+const maxSymbolPrecision = 12
+
+// Symbol names and their assets follow the rules of the symbol registry, which
+// also derives assets that are not given and rejects the ones it cannot derive
+var (
+	symbolName  = regexp.MustCompile(`^[A-Z0-9]{2,20}$`)
+	symbolAsset = regexp.MustCompile(`^[A-Z0-9]{1,10}$`)
+)
+
+// Validate checks the configuration and returns an *Error listing every problem
+func (c *Config) Validate() error {
+	problems := &Error{File: c.File}
+	c.validate(problems)
+
+	if len(problems.Problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+func (c *Config) validate(problems *Error) {
+	checkPort(problems, "server.port", c.Server.Port)
+	c.validateHealth(problems)
+	checkMin(problems, "server.stream.replay_buffer", c.Server.Stream.ReplayBuffer, 1)
+	checkMin(problems, "server.stream.client_buffer", c.Server.Stream.ClientBuffer, 1)
+	checkPositive(problems, "server.stream.heartbeat", c.Server.Stream.Heartbeat)
+	checkMin(problems, "server.websocket.send_queue", c.Server.WebSocket.SendQueue, 1)
+	checkPositive(problems, "server.websocket.ping_interval", c.Server.WebSocket.PingInterval)
+	for i, origin := range c.Server.WebSocket.AllowedOrigins {
+		checkOrigin(problems, fmt.Sprintf("server.websocket.allowed_origins[%d]", i), origin)
+	}
+	if _, err := c.Logging.SlogLevel(); err != nil {
+		problems.add("logging.level", "must be one of debug, info, warn or error, got %q", c.Logging.Level)
+	}
+
+	pg := c.Database.Postgres
+	checkRequired(problems, "database.postgres.host", pg.Host)
+	checkPort(problems, "database.postgres.port", pg.Port)
+	checkRequired(problems, "database.postgres.user", pg.User)
+	checkRequired(problems, "database.postgres.database", pg.Database)
+	if !sslModes[pg.SSLMode] {
+		problems.add("database.postgres.sslmode", "must be one of disable, require, verify-ca or verify-full, got %q", pg.SSLMode)
+	}
+
+	checkRequired(problems, "database.redis.host", c.Database.Redis.Host)
+	checkPort(problems, "database.redis.port", c.Database.Redis.Port)
+	if c.Database.Redis.Database < 0 {
+		problems.add("database.redis.db", "must not be negative")
+	}
+
+	if spool := c.Database.Spool; spool.Enabled {
+		checkRequired(problems, "database.spool.dir", spool.Dir)
+		checkMin(problems, "database.spool.max_size_mb", spool.MaxSizeMB, 1)
+		checkPositive(problems, "database.spool.replay_interval", spool.ReplayInterval)
+	}
+
+	c.validateExchanges(problems)
+
+	p := c.Processing
+	checkMin(problems, "processing.workers_per_exchange", p.WorkersPerExchange, 1)
+	checkMin(problems, "processing.batch_size", p.BatchSize, 1)
+	checkPositive(problems, "processing.aggregation_interval", p.AggregationInterval)
+	if p.AggregationInterval > 0 && (24*time.Hour)%p.AggregationInterval.Std() != 0 {
+		problems.add("processing.aggregation_interval", "must divide 24h evenly so windows align to the clock, got %s", p.AggregationInterval)
+	}
+	seenIntervals := make(map[Duration]bool)
+	for i, interval := range p.CandleIntervals {
+		key := fmt.Sprintf("processing.candle_intervals[%d]", i)
+		switch {
+		case interval <= 0 || interval.Std()%time.Second != 0:
+			problems.add(key, "must be a positive whole number of seconds, got %s", interval)
+		case seenIntervals[interval]:
+			problems.add(key, "duplicate interval %s", interval)
+		}
+		seenIntervals[interval] = true
+	}
+	c.validateWindowing(problems)
+	checkPositive(problems, "processing.failover.failover_after", p.Failover.FailoverAfter)
+	checkPositive(problems, "processing.failover.failback_after", p.Failover.FailbackAfter)
+	checkPositive(problems, "processing.failover.check_interval", p.Failover.CheckInterval)
+
+	if len(c.Symbols) == 0 {
+		problems.add("symbols", "at least one symbol is required")
+	}
+	seen := make(map[string]bool)
+	for i, symbol := range c.Symbols {
+		key := fmt.Sprintf("symbols[%d]", i)
+		checkSymbol(problems, key, symbol)
+		if seen[symbol.Name] {
+			problems.add(key, "duplicate symbol %q", symbol.Name)
+		}
+		seen[symbol.Name] = true
+	}
+}
+
+func (c *Config) validateWindowing(problems *Error) {
+	w := c.Processing.Windowing
+	if !slices.Contains(TimeSources, w.TimeSource) {
+		problems.add("processing.windowing.time_source", "must be one of %v, got %q", TimeSources, w.TimeSource)
+	}
+	if !slices.Contains(LatePolicies, w.LatePolicy) {
+		problems.add("processing.windowing.late_policy", "must be one of %v, got %q", LatePolicies, w.LatePolicy)
+	}
+	switch {
+	case w.AllowedLateness < 0:
+		problems.add("processing.windowing.allowed_lateness", "must not be negative, got %s", w.AllowedLateness)
+	case c.Processing.AggregationInterval > 0 && w.AllowedLateness >= c.Processing.AggregationInterval:
+		problems.add("processing.windowing.allowed_lateness", "must be shorter than aggregation_interval (%s)", c.Processing.AggregationInterval)
+	}
+}
+
+func (c *Config) validateExchanges(problems *Error) {
+	ex := c.Exchanges
+
+	seen := make(map[string]int)
+	for i, exchange := range ex.Live {
+		key := fmt.Sprintf("exchanges.live[%d]", i)
+
+		checkRequired(problems, key+".name", exchange.Name)
+		if first, ok := seen[exchange.Name]; ok && exchange.Name != "" {
+			problems.add(key+".name", "duplicate exchange name %q (also used by exchanges.live[%d])", exchange.Name, first)
+		} else {
+			seen[exchange.Name] = i
+		}
+		checkRequired(problems, key+".host", exchange.Host)
+		checkPort(problems, key+".port", exchange.Port)
+		checkSymbols(problems, key+".symbols", exchange.Symbols)
+	}
+
+	checkRequired(problems, "exchanges.test.host", ex.Test.Host)
+	checkPort(problems, "exchanges.test.port", ex.Test.Port)
+
+	r := ex.Reconnect
+	checkPositive(problems, "exchanges.reconnect.initial_delay", r.InitialDelay)
+	checkPositive(problems, "exchanges.reconnect.max_delay", r.MaxDelay)
+	if r.MaxDelay > 0 && r.MaxDelay < r.InitialDelay {
+		problems.add("exchanges.reconnect.max_delay", "must not be shorter than initial_delay (%s)", r.InitialDelay)
+	}
+	if r.Multiplier < 1 {
+		problems.add("exchanges.reconnect.multiplier", "must be at least 1, got %g", r.Multiplier)
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		problems.add("exchanges.reconnect.jitter", "must be between 0 and 1, got %g", r.Jitter)
+	}
+	checkMin(problems, "exchanges.reconnect.max_attempts", r.MaxAttempts, 0)
+
+	checkPositive(problems, "exchanges.stale_timeout", ex.StaleTimeout)
+
+	checkOverflow(problems, "exchanges.backpressure.live", ex.Backpressure.Live)
+	checkOverflow(problems, "exchanges.backpressure.test", ex.Backpressure.Test)
+}
+
+func (c *Config) validateHealth(problems *Error) {
+	seen := make(map[string]bool)
+	for i, name := range c.Server.Health.Critical {
+		key := fmt.Sprintf("server.health.critical[%d]", i)
+		if !slices.Contains(HealthDependencies, name) {
+			problems.add(key, "must be one of %v, got %q", HealthDependencies, name)
+		} else if seen[name] {
+			problems.add(key, "duplicate dependency %q", name)
+		}
+		seen[name] = true
+	}
+}
+
+func checkRequired(problems *Error, key, value string) {
+	if value == "" {
+		problems.add(key, "is required")
+	}
+}
+
+func checkPort(problems *Error, key string, port int) {
+	if port < 1 || port > 65535 {
+		problems.add(key, "must be between 1 and 65535, got %d", port)
+	}
+}
+
+func checkMin(problems *Error, key string, value, min int) {
+	if value < min {
+		problems.add(key, "must be at least %d, got %d", min, value)
+	}
+}
+
+func checkPositive(problems *Error, key string, d Duration) {
+	if d <= 0 {
+		problems.add(key, "must be a positive duration, got %s", d)
+	}
+}
+
+func checkSymbols(problems *Error, key string, list []string) {
+	seen := make(map[string]bool)
+	for i, symbol := range list {
+		if !symbolName.MatchString(symbol) {
+			problems.add(fmt.Sprintf("%s[%d]", key, i), "invalid symbol %q: expected 2-20 upper-case letters or digits", symbol)
+		}
+		if seen[symbol] {
+			problems.add(fmt.Sprintf("%s[%d]", key, i), "duplicate symbol %q", symbol)
+		}
+		seen[symbol] = true
+	}
+}
+
+func checkSymbol(problems *Error, key string, symbol SymbolConfig) {
+	if !symbolName.MatchString(symbol.Name) {
+		problems.add(key, "invalid symbol %q: expected 2-20 upper-case letters or digits", symbol.Name)
+		return
+	}
+	if p := symbol.Precision; p != nil && (*p < 0 || *p > maxSymbolPrecision) {
+		problems.add(key, "invalid symbol %s: precision must be between 0 and %d", symbol.Name, maxSymbolPrecision)
+	}
+	for _, asset := range []string{symbol.Base, symbol.Quote} {
+		if asset != "" && !symbolAsset.MatchString(asset) {
+			problems.add(key, "invalid symbol %s: invalid asset %q", symbol.Name, asset)
+		}
+	}
+}
+
+func checkOverflow(problems *Error, key string, cfg OverflowConfig) {
+	if !slices.Contains(OverflowPolicies, cfg.Policy) {
+		problems.add(key+".policy", "must be one of %v, got %q", OverflowPolicies, cfg.Policy)
+	}
+	checkMin(problems, key+".buffer_size", cfg.BufferSize, 1)
+}
+
+// checkOrigin accepts "*" or a bare origin: scheme, host and optional port
+func checkOrigin(problems *Error, key, origin string) {
+	if origin == "*" {
+		return
+	}
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		problems.add(key, "must be \"*\" or an origin such as https://dashboard.example.com, got %q", origin)
+	}
+}
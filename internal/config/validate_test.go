@@ -0,0 +1,99 @@
+package config_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"marketflow/internal/application/usecases"
+	"marketflow/internal/concurrency"
+	"marketflow/internal/config"
+	"marketflow/internal/domain/aggregation"
+	"marketflow/internal/domain/symbols"
+)
+
+// The config package validates enum values against its own lists so it does
+// not depend on the packages that implement them; these tests keep them in step
+
+func TestValidationListsMatchTheirImplementations(t *testing.T) {
+	for _, tt := range []struct {
+		key         string
+		config, got []string
+	}{
+		{"exchanges.backpressure.*.policy", config.OverflowPolicies, stringsOf(concurrency.OverflowPolicies)},
+		{"processing.windowing.time_source", config.TimeSources, stringsOf(aggregation.TimeSources)},
+		{"processing.windowing.late_policy", config.LatePolicies, stringsOf(aggregation.LatePolicies)},
+	} {
+		if !slices.Equal(tt.config, tt.got) {
+			t.Errorf("%s: config accepts %v, implemented are %v", tt.key, tt.config, tt.got)
+		}
+	}
+}
+
+func TestSymbolValidationMatchesTheRegistry(t *testing.T) {
+	precision := func(n int) *int { return &n }
+
+	for _, symbol := range []config.SymbolConfig{
+		{Name: "BTCUSDT"},
+		{Name: "B"},
+		{Name: "btcusdt"},
+		{Name: "ABCDEFGHIJKLMNOPQRSTU"},
+		{Name: "BTCUSDT", Precision: precision(0)},
+		{Name: "BTCUSDT", Precision: precision(12)},
+		{Name: "BTCUSDT", Precision: precision(13)},
+		{Name: "BTCUSDT", Precision: precision(-1)},
+		{Name: "BTCUSDT", Base: "BTC", Quote: "USDT"},
+		{Name: "BTCUSDT", Base: "btc"},
+		{Name: "BTCUSDT", Quote: "TOOLONGQUOTE"},
+	} {
+		t.Run(fmt.Sprintf("%+v", symbol), func(t *testing.T) {
+			cfg := config.Defaults()
+			cfg.Symbols = []config.SymbolConfig{symbol}
+			var problem string
+			if err := cfg.Validate(); err != nil {
+				problem = err.(*config.Error).Problems[0]
+			}
+
+			_, registryErr := symbols.NewRegistry(usecases.TrackedSymbols(cfg.Symbols))
+			switch {
+			case registryErr == nil && problem != "":
+				t.Fatalf("config rejects a symbol the registry accepts: %s", problem)
+			case registryErr != nil && problem == "":
+				t.Fatalf("config accepts a symbol the registry rejects: %v", registryErr)
+			case registryErr != nil && !strings.HasSuffix(problem, registryErr.Error()):
+				t.Fatalf("config reports %q, the registry %q", problem, registryErr)
+			}
+		})
+	}
+}
+
+func TestValidateAllowedOrigins(t *testing.T) {
+	for _, tt := range []struct {
+		origin string
+		valid  bool
+	}{
+		{"*", true},
+		{"https://dashboard.example.com", true},
+		{"http://localhost:3000", true},
+		{"dashboard.example.com", false},
+		{"https://dashboard.example.com/", false},
+		{"https://dashboard.example.com/app", false},
+		{"ftp://dashboard.example.com", false},
+		{"https://user@dashboard.example.com", false},
+	} {
+		cfg := config.Defaults()
+		cfg.Server.WebSocket.AllowedOrigins = []string{tt.origin}
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%q: got %v, want valid %v", tt.origin, err, tt.valid)
+		}
+	}
+}
+
+func stringsOf[T ~string](values []T) []string {
+	list := make([]string, 0, len(values))
+	for _, value := range values {
+		list = append(list, string(value))
+	}
+	return list
+}
@@ -0,0 +1,147 @@
+package aggregation
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+// IntervalName formats an interval compactly, e.g. 1s, 5m, 1h, 1h30m
+func IntervalName(interval time.Duration) string {
+	name := interval.String()
+	if strings.HasSuffix(name, "m0s") {
+		name = strings.TrimSuffix(name, "0s")
+	}
+	if strings.HasSuffix(name, "h0m") {
+		name = strings.TrimSuffix(name, "0m")
+	}
+	return name
+}
+
+type candleKey struct {
+	symbol   string
+	exchange string
+	interval time.Duration
+	start    time.Time
+}
+
+// CandleBuilder builds OHLC candles at several intervals from a stream of price updates.
+// Windows are aligned to multiples of the interval since the Unix epoch, so a 1m candle
+// always covers hh:mm:00 to hh:mm+1:00. A candle stays open for the allowed lateness
+// after its window ends, so ticks delayed on their way to the builder still count;
+// ticks for a candle already emitted are handed back to the caller.
+type CandleBuilder struct {
+	mu        sync.Mutex
+	intervals []time.Duration
+	lateness  time.Duration
+	open      map[candleKey]*models.Candle
+	flushedAt time.Time // windows ending at or before this have been emitted
+}
+
+// NewCandleBuilder creates a builder for the given intervals that emits a candle
+// once lateness has passed since its window ended
+func NewCandleBuilder(intervals []time.Duration, lateness time.Duration) *CandleBuilder {
+	if lateness < 0 {
+		lateness = 0
+	}
+	return &CandleBuilder{
+		intervals: intervals,
+		lateness:  lateness,
+		open:      make(map[candleKey]*models.Candle),
+	}
+}
+
+// Intervals returns the intervals candles are built at
+func (b *CandleBuilder) Intervals() []time.Duration {
+	return b.intervals
+}
+
+// Add folds a price update into the candle of its window at every interval,
+// placing it at time at. For every interval whose candle of that window was
+// already emitted it returns a candle of just this update instead, so the
+// caller can apply its late policy.
+func (b *CandleBuilder) Add(update models.PriceUpdate, at time.Time) []models.Candle {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var late []models.Candle
+	for _, interval := range b.intervals {
+		start := at.Truncate(interval)
+		if !start.Add(interval).After(b.flushedAt) {
+			// Later than the allowed lateness: the window has already been emitted
+			late = append(late, newCandle(update, interval, start, at))
+			continue
+		}
+
+		key := candleKey{update.Symbol, update.Exchange, interval, start}
+		candle, ok := b.open[key]
+		if !ok {
+			first := newCandle(update, interval, start, at)
+			b.open[key] = &first
+			continue
+		}
+
+		if update.Price > candle.High {
+			candle.High = update.Price
+		}
+		if update.Price < candle.Low {
+			candle.Low = update.Price
+		}
+		if at.Before(candle.FirstTickAt) {
+			candle.FirstTickAt = at
+			candle.Open = update.Price
+		}
+		if !at.Before(candle.LastTickAt) {
+			candle.LastTickAt = at
+			candle.Close = update.Price
+		}
+		candle.TickCount++
+	}
+	return late
+}
+
+// newCandle returns the candle of a single update
+func newCandle(update models.PriceUpdate, interval time.Duration, start, at time.Time) models.Candle {
+	return models.Candle{
+		PairName:    update.Symbol,
+		Exchange:    update.Exchange,
+		Interval:    IntervalName(interval),
+		OpenTime:    start,
+		CloseTime:   start.Add(interval),
+		Open:        update.Price,
+		High:        update.Price,
+		Low:         update.Price,
+		Close:       update.Price,
+		TickCount:   1,
+		FirstTickAt: at,
+		LastTickAt:  at,
+	}
+}
+
+// Flush returns every candle whose window ended at least the allowed lateness
+// before now, oldest first
+func (b *CandleBuilder) Flush(now time.Time) []models.Candle {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cutoff := now.Add(-b.lateness)
+	if cutoff.After(b.flushedAt) {
+		b.flushedAt = cutoff
+	}
+
+	var completed []models.Candle
+	for key, candle := range b.open {
+		if !candle.CloseTime.After(b.flushedAt) {
+			completed = append(completed, *candle)
+			delete(b.open, key)
+		}
+	}
+
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i].OpenTime.Before(completed[j].OpenTime)
+	})
+	return completed
+}
@@ -0,0 +1,48 @@
+package aggregation
+
+import (
+	"testing"
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+func TestCandleBuilderKeepsTicksDelayedPastTheBoundary(t *testing.T) {
+	builder := NewCandleBuilder([]time.Duration{time.Minute}, time.Second)
+	start := time.Date(2026, 1, 1, 12, 3, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+	add := func(price float64, at time.Time) []models.Candle {
+		return builder.Add(models.PriceUpdate{Symbol: "BTCUSDT", Exchange: "exchange1", Price: price, ReceivedAt: at}, at)
+	}
+
+	add(100, start.Add(10*time.Second))
+	add(101, end.Add(100*time.Millisecond))
+
+	// The flush tick right after the boundary must not emit the window yet
+	if candles := builder.Flush(end.Add(200 * time.Millisecond)); len(candles) != 0 {
+		t.Fatalf("emitted %d candles within the allowed lateness", len(candles))
+	}
+
+	// A tick of the closed window reaching the builder after that flush still counts
+	add(105, end.Add(-time.Millisecond))
+
+	candles := builder.Flush(end.Add(time.Second))
+	if len(candles) != 1 {
+		t.Fatalf("got %d candles, want 1", len(candles))
+	}
+	candle := candles[0]
+	if !candle.OpenTime.Equal(start) || candle.TickCount != 2 || candle.High != 105 || candle.Close != 105 {
+		t.Fatalf("unexpected candle %+v", candle)
+	}
+
+	// Beyond the allowed lateness the tick is not added to the emitted candle
+	// but handed back as a candle of its own
+	late := add(90, start.Add(time.Second))
+	if len(late) != 1 || !late[0].OpenTime.Equal(start) || late[0].TickCount != 1 || late[0].Low != 90 || late[0].Close != 90 {
+		t.Fatalf("unexpected late candles %+v", late)
+	}
+	candles = builder.Flush(end.Add(time.Minute + time.Second))
+	if len(candles) != 1 || !candles[0].OpenTime.Equal(end) || candles[0].TickCount != 1 {
+		t.Fatalf("unexpected candles after the next window %+v", candles)
+	}
+}
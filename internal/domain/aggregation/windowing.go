@@ -0,0 +1,64 @@
+package aggregation
+
+import (
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+// TimeSource decides which clock places an update in an aggregation window
+type TimeSource string
+
+const (
+	// TimeReceived uses the time the update was received locally
+	TimeReceived TimeSource = "received"
+	// TimeEvent uses the millisecond timestamp sent by the exchange
+	TimeEvent TimeSource = "event"
+)
+
+// TimeSources lists every supported time source
+var TimeSources = []TimeSource{TimeReceived, TimeEvent}
+
+// IsValid reports whether the time source is supported
+func (s TimeSource) IsValid() bool {
+	for _, source := range TimeSources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeOf returns the time of an update according to the source; updates
+// without an exchange timestamp fall back to the time they were received
+func (s TimeSource) TimeOf(update models.PriceUpdate) time.Time {
+	if s == TimeEvent && update.Timestamp > 0 {
+		return time.UnixMilli(update.Timestamp)
+	}
+	return update.ReceivedAt
+}
+
+// LatePolicy decides what happens to an update whose window was already aggregated
+type LatePolicy string
+
+const (
+	// LateDrop discards the update
+	LateDrop LatePolicy = "drop"
+	// LateAmend folds the update into the persisted window
+	LateAmend LatePolicy = "amend"
+	// LateCorrect records the update in the corrections table, leaving the window untouched
+	LateCorrect LatePolicy = "correct"
+)
+
+// LatePolicies lists every supported late policy
+var LatePolicies = []LatePolicy{LateDrop, LateAmend, LateCorrect}
+
+// IsValid reports whether the policy is supported
+func (p LatePolicy) IsValid() bool {
+	for _, policy := range LatePolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package aggregation
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+type windowKey struct {
+	symbol   string
+	exchange string
+	start    time.Time
+}
+
+// windowStats holds the running statistics of one open window
+type windowStats struct {
+	count int64
+	sum   float64
+	min   float64
+	max   float64
+}
+
+// WindowAggregator keeps running average, min and max prices per symbol,
+// exchange and aligned window, so a window is aggregated without reading its
+// updates back. Windows ending at or before the watermark are closed and
+// reject further updates.
+type WindowAggregator struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	open      map[windowKey]*windowStats
+	watermark time.Time
+}
+
+// NewWindowAggregator creates an aggregator for windows of the given width
+func NewWindowAggregator(interval time.Duration) *WindowAggregator {
+	return &WindowAggregator{
+		interval: interval,
+		open:     make(map[windowKey]*windowStats),
+	}
+}
+
+// Add folds a price update into the window containing at; it reports false,
+// leaving the window untouched, when that window is already closed
+func (a *WindowAggregator) Add(update models.PriceUpdate, at time.Time) bool {
+	start := at.Truncate(a.interval)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !start.Add(a.interval).After(a.watermark) {
+		return false
+	}
+
+	key := windowKey{update.Symbol, update.Exchange, start}
+	stats, ok := a.open[key]
+	if !ok {
+		stats = &windowStats{min: update.Price, max: update.Price}
+		a.open[key] = stats
+	}
+
+	stats.count++
+	stats.sum += update.Price
+	if update.Price < stats.min {
+		stats.min = update.Price
+	}
+	if update.Price > stats.max {
+		stats.max = update.Price
+	}
+	return true
+}
+
+// Close moves the watermark to end and returns every window ending at or
+// before it, oldest first
+func (a *WindowAggregator) Close(end time.Time) []models.AggregatedData {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if end.After(a.watermark) {
+		a.watermark = end
+	}
+
+	var closed []models.AggregatedData
+	for key, stats := range a.open {
+		windowEnd := key.start.Add(a.interval)
+		if windowEnd.After(a.watermark) {
+			continue
+		}
+
+		closed = append(closed, models.AggregatedData{
+			PairName:     key.symbol,
+			Exchange:     key.exchange,
+			Timestamp:    key.start,
+			WindowStart:  key.start,
+			WindowEnd:    windowEnd,
+			Interval:     IntervalName(a.interval),
+			AveragePrice: stats.sum / float64(stats.count),
+			MinPrice:     stats.min,
+			MaxPrice:     stats.max,
+			TickCount:    stats.count,
+		})
+		delete(a.open, key)
+	}
+
+	sort.Slice(closed, func(i, j int) bool {
+		if !closed[i].WindowStart.Equal(closed[j].WindowStart) {
+			return closed[i].WindowStart.Before(closed[j].WindowStart)
+		}
+		if closed[i].PairName != closed[j].PairName {
+			return closed[i].PairName < closed[j].PairName
+		}
+		return closed[i].Exchange < closed[j].Exchange
+	})
+	return closed
+}
+
+// Watermark returns the end of the most recently closed window
+func (a *WindowAggregator) Watermark() time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.watermark
+}
+
+// OpenWindows returns the number of windows currently accumulating updates
+func (a *WindowAggregator) OpenWindows() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.open)
+}
@@ -0,0 +1,213 @@
+package aggregation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"marketflow/internal/domain/models"
+)
+
+const (
+	benchSymbols        = 5
+	benchExchanges      = 3
+	benchTicksPerWindow = 200 // per symbol and exchange
+)
+
+var benchStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// benchUpdates returns the updates of one minute window for every symbol and exchange
+func benchUpdates() []models.PriceUpdate {
+	var updates []models.PriceUpdate
+	step := time.Minute / benchTicksPerWindow
+	for i := 0; i < benchTicksPerWindow; i++ {
+		for s := 0; s < benchSymbols; s++ {
+			for e := 0; e < benchExchanges; e++ {
+				at := benchStart.Add(time.Duration(i) * step)
+				updates = append(updates, models.PriceUpdate{
+					Symbol:     fmt.Sprintf("SYM%dUSDT", s),
+					Exchange:   fmt.Sprintf("exchange%d", e+1),
+					Price:      100 + float64(i%17),
+					Timestamp:  at.UnixMilli(),
+					ReceivedAt: at,
+				})
+			}
+		}
+	}
+	return updates
+}
+
+// readBack reproduces the aggregation the WindowAggregator replaced: every
+// update is encoded into a per symbol and exchange history sorted by receive
+// time, as the Redis adapter stores it, and closing a window reads the history
+// of every symbol and exchange back, decodes it and computes the statistics.
+// It runs in memory, so it leaves out the Redis round trips and flatters the
+// old path.
+type readBack struct {
+	history map[string][]readBackEntry
+}
+
+type readBackEntry struct {
+	score int64
+	data  []byte
+}
+
+func newReadBack() *readBack {
+	return &readBack{history: make(map[string][]readBackEntry)}
+}
+
+func (r *readBack) add(update models.PriceUpdate) {
+	data, _ := json.Marshal(update)
+	key := update.Exchange + ":" + update.Symbol
+	r.history[key] = append(r.history[key], readBackEntry{score: update.ReceivedAt.UnixMilli(), data: data})
+}
+
+func (r *readBack) close(symbols, exchanges []string, start, end time.Time) []models.AggregatedData {
+	var rows []models.AggregatedData
+	for _, symbol := range symbols {
+		for _, exchange := range exchanges {
+			entries := r.history[exchange+":"+symbol]
+			from := sort.Search(len(entries), func(i int) bool { return entries[i].score >= start.UnixMilli() })
+			to := sort.Search(len(entries), func(i int) bool { return entries[i].score >= end.UnixMilli() })
+
+			var history []models.PriceUpdate
+			for _, entry := range entries[from:to] {
+				var update models.PriceUpdate
+				if err := json.Unmarshal(entry.data, &update); err != nil {
+					continue
+				}
+				history = append(history, update)
+			}
+			if len(history) == 0 {
+				continue
+			}
+
+			var total float64
+			min, max := history[0].Price, history[0].Price
+			for _, update := range history {
+				total += update.Price
+				if update.Price < min {
+					min = update.Price
+				}
+				if update.Price > max {
+					max = update.Price
+				}
+			}
+			rows = append(rows, models.AggregatedData{
+				PairName:     symbol,
+				Exchange:     exchange,
+				Timestamp:    start,
+				WindowStart:  start,
+				WindowEnd:    end,
+				AveragePrice: total / float64(len(history)),
+				MinPrice:     min,
+				MaxPrice:     max,
+				TickCount:    int64(len(history)),
+			})
+		}
+	}
+	return rows
+}
+
+func benchNames() (symbols, exchanges []string) {
+	for s := 0; s < benchSymbols; s++ {
+		symbols = append(symbols, fmt.Sprintf("SYM%dUSDT", s))
+	}
+	for e := 0; e < benchExchanges; e++ {
+		exchanges = append(exchanges, fmt.Sprintf("exchange%d", e+1))
+	}
+	return symbols, exchanges
+}
+
+func TestWindowAggregatorMatchesReadBack(t *testing.T) {
+	updates := benchUpdates()
+	symbols, exchanges := benchNames()
+	end := benchStart.Add(time.Minute)
+
+	aggregator := NewWindowAggregator(time.Minute)
+	baseline := newReadBack()
+	for _, update := range updates {
+		aggregator.Add(update, update.ReceivedAt)
+		baseline.add(update)
+	}
+
+	got := aggregator.Close(end)
+	want := baseline.close(symbols, exchanges, benchStart, end)
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(got), len(want))
+	}
+
+	byKey := make(map[string]models.AggregatedData)
+	for _, row := range want {
+		byKey[row.PairName+"/"+row.Exchange] = row
+	}
+	for _, row := range got {
+		expected := byKey[row.PairName+"/"+row.Exchange]
+		if row.TickCount != expected.TickCount || row.MinPrice != expected.MinPrice ||
+			row.MaxPrice != expected.MaxPrice || row.AveragePrice != expected.AveragePrice {
+			t.Fatalf("%s/%s: got %+v, want %+v", row.PairName, row.Exchange, row, expected)
+		}
+	}
+}
+
+func BenchmarkWindowAggregator_Add(b *testing.B) {
+	updates := benchUpdates()
+
+	b.Run("aggregator", func(b *testing.B) {
+		aggregator := NewWindowAggregator(time.Minute)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			update := updates[i%len(updates)]
+			aggregator.Add(update, update.ReceivedAt)
+		}
+	})
+
+	b.Run("readback", func(b *testing.B) {
+		baseline := newReadBack()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if i%len(updates) == 0 {
+				baseline = newReadBack()
+			}
+			baseline.add(updates[i%len(updates)])
+		}
+	})
+}
+
+// BenchmarkWindowAggregator_Close measures closing one window holding
+// benchTicksPerWindow updates for every symbol and exchange
+func BenchmarkWindowAggregator_Close(b *testing.B) {
+	updates := benchUpdates()
+	symbols, exchanges := benchNames()
+	end := benchStart.Add(time.Minute)
+
+	b.Run("aggregator", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			aggregator := NewWindowAggregator(time.Minute)
+			for _, update := range updates {
+				aggregator.Add(update, update.ReceivedAt)
+			}
+			b.StartTimer()
+
+			aggregator.Close(end)
+		}
+	})
+
+	b.Run("readback", func(b *testing.B) {
+		baseline := newReadBack()
+		for _, update := range updates {
+			baseline.add(update)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			baseline.close(symbols, exchanges, benchStart, end)
+		}
+	})
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Candle represents an OHLC candle of one symbol on one exchange over a fixed interval
+type Candle struct {
+	PairName    string    `json:"symbol"`
+	Exchange    string    `json:"exchange"`
+	Interval    string    `json:"interval"`
+	OpenTime    time.Time `json:"open_time"`  // inclusive window start
+	CloseTime   time.Time `json:"close_time"` // exclusive window end
+	Open        float64   `json:"open"`
+	High        float64   `json:"high"`
+	Low         float64   `json:"low"`
+	Close       float64   `json:"close"`
+	TickCount   int64     `json:"tick_count"`
+	FirstTickAt time.Time `json:"first_tick_at"`
+	LastTickAt  time.Time `json:"last_tick_at"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// ConnectionState represents the state of an upstream exchange connection
+type ConnectionState string
+
+const (
+	ConnectionStateConnecting   ConnectionState = "connecting"
+	ConnectionStateConnected    ConnectionState = "connected"
+	ConnectionStateStale        ConnectionState = "stale"
+	ConnectionStateBackingOff   ConnectionState = "backing_off"
+	ConnectionStateFailed       ConnectionState = "failed"
+	ConnectionStateDisconnected ConnectionState = "disconnected"
+	ConnectionStateDisabled     ConnectionState = "disabled"
+	ConnectionStatePaused       ConnectionState = "paused"
+)
+
+// ExchangeStatus represents the connection status of a single upstream exchange
+type ExchangeStatus struct {
+	Name          string          `json:"name"`
+	Address       string          `json:"address,omitempty"`
+	State         ConnectionState `json:"state"`
+	LastError     string          `json:"last_error,omitempty"`
+	Reconnects    int             `json:"reconnects"`
+	StaleCount    int             `json:"stale_count"`
+	LastMessageAt *time.Time      `json:"last_message_at,omitempty"`
+	NextRetryAt   *time.Time      `json:"next_retry_at,omitempty"`
+}
+
+// SourceTransition records a switch of the active data source
+type SourceTransition struct {
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Reason string    `json:"reason"`
+	At     time.Time `json:"at"`
+}
+
+// FailoverStatus represents the state of automatic live/test failover
+type FailoverStatus struct {
+	Enabled      bool               `json:"enabled"`
+	Active       bool               `json:"active"`
+	ActiveSource string             `json:"active_source"`
+	Transitions  []SourceTransition `json:"transitions"`
+}
+
+// OverflowCounter counts updates lost to a full queue for one exchange and symbol
+type OverflowCounter struct {
+	Exchange  string `json:"exchange"`
+	Symbol    string `json:"symbol"`
+	Dropped   uint64 `json:"dropped"`
+	Coalesced uint64 `json:"coalesced"`
+}
+
+// OverflowStats describes the backpressure state of a data source
+type OverflowStats struct {
+	Source    string            `json:"source"`
+	Policy    string            `json:"policy"`
+	Capacity  int               `json:"capacity"`
+	Queued    int               `json:"queued"`
+	Dropped   uint64            `json:"dropped"`
+	Coalesced uint64            `json:"coalesced"`
+	Counters  []OverflowCounter `json:"counters"`
+}
+
+// LatenessStats reports how updates arriving after their window was aggregated were handled
+type LatenessStats struct {
+	TimeSource      string        `json:"time_source"`
+	AllowedLateness string        `json:"allowed_lateness"`
+	LatePolicy      string        `json:"late_policy"`
+	Watermark       time.Time     `json:"watermark"` // windows ending at or before it are closed
+	OpenWindows     int           `json:"open_windows"`
+	Late            uint64        `json:"late"`
+	LateCandles     uint64        `json:"late_candles"` // updates for candles already emitted
+	Failed          uint64        `json:"failed"`       // late updates the policy could not be applied to
+	Skewed          uint64        `json:"skewed"`       // updates stamped too far ahead, placed by receive time
+	Exchanges       []LateCounter `json:"exchanges"`
+}
+
+// LateCounter counts late updates for one exchange
+type LateCounter struct {
+	Exchange    string `json:"exchange"`
+	Late        uint64 `json:"late"`
+	LateCandles uint64 `json:"late_candles"`
+	Dropped     uint64 `json:"dropped"`
+	Amended     uint64 `json:"amended"`
+	Corrected   uint64 `json:"corrected"`
+	Failed      uint64 `json:"failed"`
+}
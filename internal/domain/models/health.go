@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// DependencyState represents the state of the connection to a backing service
+type DependencyState string
+
+const (
+	DependencyConnecting DependencyState = "connecting"
+	DependencyUp         DependencyState = "up"
+	DependencyDown       DependencyState = "down"
+)
+
+// DependencyHealth reports whether a backing service is reachable
+type DependencyHealth struct {
+	Name      string          `json:"name"`
+	State     DependencyState `json:"state"`
+	Since     time.Time       `json:"since"` // when the state last changed
+	LastCheck time.Time       `json:"last_check,omitempty"`
+	LatencyMs float64         `json:"latency_ms,omitempty"` // duration of the last check
+	LastError string          `json:"last_error,omitempty"`
+	Critical  bool            `json:"critical"` // whether the service is not ready without it
+}
@@ -4,22 +4,43 @@ import "time"
 
 // PriceUpdate represents a real-time price update from an exchange
 type PriceUpdate struct {
-	Symbol    string    `json:"symbol"`
-	Price     float64   `json:"price"`
-	Timestamp int64     `json:"timestamp"`
-	Exchange  string    `json:"exchange"`
+	Symbol     string    `json:"symbol"`
+	Price      float64   `json:"price"`
+	Timestamp  int64     `json:"timestamp"`
+	Exchange   string    `json:"exchange"`
 	ReceivedAt time.Time `json:"received_at"`
 }
 
 // AggregatedData represents aggregated market data stored in PostgreSQL
 type AggregatedData struct {
-	ID           int64     `db:"id"`
-	PairName     string    `db:"pair_name"`
-	Exchange     string    `db:"exchange"`
-	Timestamp    time.Time `db:"timestamp"`
-	AveragePrice float64   `db:"average_price"`
-	MinPrice     float64   `db:"min_price"`
-	MaxPrice     float64   `db:"max_price"`
+	ID           int64     `db:"id" json:"id"`
+	PairName     string    `db:"pair_name" json:"symbol"`
+	Exchange     string    `db:"exchange" json:"exchange"`
+	Timestamp    time.Time `db:"timestamp" json:"timestamp"`       // same as WindowStart for aggregated windows
+	WindowStart  time.Time `db:"window_start" json:"window_start"` // inclusive
+	WindowEnd    time.Time `db:"window_end" json:"window_end"`     // exclusive
+	Interval     string    `db:"interval" json:"interval"`         // window width, e.g. 1m
+	AveragePrice float64   `db:"average_price" json:"average_price"`
+	MinPrice     float64   `db:"min_price" json:"min_price"`
+	MaxPrice     float64   `db:"max_price" json:"max_price"`
+	TickCount    int64     `db:"tick_count" json:"tick_count"` // updates folded into the window
+}
+
+// AggregatedCursor identifies the last row of a page of aggregated data
+type AggregatedCursor struct {
+	WindowStart time.Time
+	ID          int64
+}
+
+// Correction records a price update that arrived after its window was aggregated
+type Correction struct {
+	PairName    string    `db:"pair_name"`
+	Exchange    string    `db:"exchange"`
+	WindowStart time.Time `db:"window_start"`
+	WindowEnd   time.Time `db:"window_end"`
+	Price       float64   `db:"price"`
+	EventTime   time.Time `db:"event_time"`
+	ReceivedAt  time.Time `db:"received_at"`
 }
 
 // LatestPrice represents cached latest price data in Redis
@@ -37,3 +58,19 @@ const (
 	DataModeLive DataMode = "live"
 	DataModeTest DataMode = "test"
 )
+
+// SpoolStatus reports the batches waiting on disk for the database to accept them
+type SpoolStatus struct {
+	Enabled      bool       `json:"enabled"`
+	Dir          string     `json:"dir,omitempty"`
+	SizeBytes    int64      `json:"size_bytes"`
+	Batches      int        `json:"batches"`
+	Rows         int        `json:"rows"`
+	OldestAt     *time.Time `json:"oldest_at,omitempty"`
+	AgeSeconds   float64    `json:"age_seconds"`     // age of the oldest batch
+	Dropped      uint64     `json:"dropped_batches"` // batches lost because the spool was full
+	Replayed     uint64     `json:"replayed_batches"`
+	Quarantined  uint64     `json:"quarantined_batches"` // batches the database rejected on replay
+	LastReplayAt *time.Time `json:"last_replay_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ReloadResult reports how a configuration reload was applied
+type ReloadResult struct {
+	File            string    `json:"file,omitempty"`
+	Applied         []string  `json:"applied"`
+	RestartRequired []string  `json:"restart_required"`
+	Failed          []string  `json:"failed,omitempty"`
+	ReloadedAt      time.Time `json:"reloaded_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// StreamStats represents the state of the live price stream
+type StreamStats struct {
+	Subscribers int    `json:"subscribers"`
+	Published   uint64 `json:"published"`
+	Evicted     uint64 `json:"evicted"`  // subscribers dropped for falling behind
+	Buffered    int    `json:"buffered"` // events kept for resuming
+}
+
+// StreamChannel names a kind of message of the subscription API
+type StreamChannel string
+
+const (
+	ChannelTicks   StreamChannel = "ticks"
+	ChannelCandles StreamChannel = "candles"
+	ChannelSpreads StreamChannel = "spreads"
+)
+
+// StreamChannels lists every channel of the subscription API
+var StreamChannels = []StreamChannel{ChannelTicks, ChannelCandles, ChannelSpreads}
+
+// StreamMessage is a tick, completed candle or spread published on a channel
+type StreamMessage struct {
+	Channel  StreamChannel
+	Symbol   string
+	Exchange string // empty for spreads, which span exchanges
+	Data     interface{}
+}
+
+// Spread represents the gap between the highest and lowest latest price of a symbol across exchanges
+type Spread struct {
+	Symbol       string    `json:"symbol"`
+	High         float64   `json:"high"`
+	HighExchange string    `json:"high_exchange"`
+	Low          float64   `json:"low"`
+	LowExchange  string    `json:"low_exchange"`
+	Spread       float64   `json:"spread"`
+	SpreadBps    float64   `json:"spread_bps"` // spread relative to the low price, in basis points
+	Exchanges    int       `json:"exchanges"`  // exchanges with a recent price
+	Timestamp    time.Time `json:"timestamp"`
+}
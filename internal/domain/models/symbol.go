@@ -0,0 +1,9 @@
+package models
+
+// Symbol describes a tracked trading pair
+type Symbol struct {
+	Name      string `json:"name"`
+	Base      string `json:"base"`
+	Quote     string `json:"quote"`
+	Precision int    `json:"precision"` // decimal places prices are rounded to
+}
@@ -0,0 +1,180 @@
+package symbols
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"marketflow/internal/domain/models"
+)
+
+// DefaultPrecision is the number of decimal places used when a symbol does not set one
+const DefaultPrecision = 2
+
+// MaxPrecision is the largest supported number of decimal places
+const MaxPrecision = 12
+
+var (
+	// ErrInvalidSymbol is returned for malformed symbol definitions
+	ErrInvalidSymbol = errors.New("invalid symbol")
+
+	// ErrSymbolNotFound is returned when a symbol is not tracked
+	ErrSymbolNotFound = errors.New("symbol not found")
+
+	// ErrSymbolExists is returned when adding a symbol that is already tracked
+	ErrSymbolExists = errors.New("symbol already exists")
+)
+
+var (
+	namePattern  = regexp.MustCompile(`^[A-Z0-9]{2,20}$`)
+	assetPattern = regexp.MustCompile(`^[A-Z0-9]{1,10}$`)
+)
+
+// quoteAssets are recognised at the end of a symbol name when its base and quote are not given
+var quoteAssets = []string{"USDT", "USDC", "BUSD", "USD", "EUR", "BTC", "ETH"}
+
+// ValidName reports whether a symbol name is well formed: 2-20 upper-case letters or digits
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Normalize validates a symbol and derives its base and quote assets from the name when omitted
+func Normalize(symbol models.Symbol) (models.Symbol, error) {
+	if !ValidName(symbol.Name) {
+		return symbol, fmt.Errorf("%w %q: expected 2-20 upper-case letters or digits", ErrInvalidSymbol, symbol.Name)
+	}
+	if symbol.Precision < 0 || symbol.Precision > MaxPrecision {
+		return symbol, fmt.Errorf("%w %s: precision must be between 0 and %d", ErrInvalidSymbol, symbol.Name, MaxPrecision)
+	}
+
+	switch {
+	case symbol.Base == "" && symbol.Quote == "":
+		for _, quote := range quoteAssets {
+			if base := strings.TrimSuffix(symbol.Name, quote); base != symbol.Name && base != "" {
+				symbol.Base, symbol.Quote = base, quote
+				break
+			}
+		}
+	case symbol.Base == "":
+		symbol.Base = strings.TrimSuffix(symbol.Name, symbol.Quote)
+	case symbol.Quote == "":
+		symbol.Quote = strings.TrimPrefix(symbol.Name, symbol.Base)
+	}
+
+	for _, asset := range []string{symbol.Base, symbol.Quote} {
+		if asset != "" && !assetPattern.MatchString(asset) {
+			return symbol, fmt.Errorf("%w %s: invalid asset %q", ErrInvalidSymbol, symbol.Name, asset)
+		}
+	}
+
+	return symbol, nil
+}
+
+// Registry is the set of tracked symbols shared by the generator, the aggregator and the API
+type Registry struct {
+	mu      sync.RWMutex
+	symbols map[string]models.Symbol
+	order   []string
+}
+
+// NewRegistry creates a registry holding the given symbols
+func NewRegistry(symbols []models.Symbol) (*Registry, error) {
+	r := &Registry{symbols: make(map[string]models.Symbol)}
+	if err := r.Replace(symbols); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Replace swaps the whole symbol set; nothing changes if any symbol is invalid
+func (r *Registry) Replace(symbols []models.Symbol) error {
+	byName := make(map[string]models.Symbol, len(symbols))
+	order := make([]string, 0, len(symbols))
+
+	for _, symbol := range symbols {
+		normalized, err := Normalize(symbol)
+		if err != nil {
+			return err
+		}
+		if _, exists := byName[normalized.Name]; exists {
+			return fmt.Errorf("%w: %s", ErrSymbolExists, normalized.Name)
+		}
+		byName[normalized.Name] = normalized
+		order = append(order, normalized.Name)
+	}
+
+	r.mu.Lock()
+	r.symbols = byName
+	r.order = order
+	r.mu.Unlock()
+	return nil
+}
+
+// List returns every tracked symbol in registration order
+func (r *Registry) List() []models.Symbol {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]models.Symbol, 0, len(r.order))
+	for _, name := range r.order {
+		list = append(list, r.symbols[name])
+	}
+	return list
+}
+
+// Names returns the names of every tracked symbol in registration order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.order))
+	copy(names, r.order)
+	return names
+}
+
+// Get returns a tracked symbol by name
+func (r *Registry) Get(name string) (models.Symbol, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	symbol, ok := r.symbols[name]
+	return symbol, ok
+}
+
+// Add starts tracking a symbol
+func (r *Registry) Add(symbol models.Symbol) (models.Symbol, error) {
+	normalized, err := Normalize(symbol)
+	if err != nil {
+		return symbol, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.symbols[normalized.Name]; exists {
+		return normalized, fmt.Errorf("%w: %s", ErrSymbolExists, normalized.Name)
+	}
+	r.symbols[normalized.Name] = normalized
+	r.order = append(r.order, normalized.Name)
+	return normalized, nil
+}
+
+// Remove stops tracking a symbol
+func (r *Registry) Remove(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.symbols[name]; !exists {
+		return fmt.Errorf("%w: %s", ErrSymbolNotFound, name)
+	}
+	delete(r.symbols, name)
+	for i, tracked := range r.order {
+		if tracked == name {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
@@ -5,10 +5,10 @@ import (
 	"os"
 )
 
-// New creates a new structured logger
-func New() *slog.Logger {
+// New creates a new structured logger; level may be a *slog.LevelVar so it can change at runtime
+func New(level slog.Leveler) *slog.Logger {
 	opts := &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: level,
 	}
 
 	handler := slog.NewJSONHandler(os.Stdout, opts)